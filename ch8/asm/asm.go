@@ -0,0 +1,43 @@
+// Package asm implements a two-pass assembler and disassembler for
+// the CHIP-8 opcode table decoded by ch8.VirtualMachine.
+package asm
+
+import "fmt"
+
+// Instruction is a single decoded (or assembled) CHIP-8 instruction.
+type Instruction struct {
+	// Addr is the memory address the instruction is loaded at.
+	Addr uint16
+
+	// Opcode is the raw 16-bit opcode.
+	Opcode uint16
+
+	// Mnemonic is the instruction's textual mnemonic, e.g. "LD".
+	Mnemonic string
+
+	// Operands is the textual operand list, e.g. "V3, 0x1A".
+	Operands string
+
+	// Label is the label a jump/call target resolves to, if any.
+	Label string
+}
+
+// String formats the instruction the way Disassemble's output is
+// meant to be read: "<addr>: <mnemonic> <operands>".
+func (ins Instruction) String() string {
+	if ins.Operands == "" {
+		return fmt.Sprintf("%03X: %s", ins.Addr, ins.Mnemonic)
+	}
+	return fmt.Sprintf("%03X: %s %s", ins.Addr, ins.Mnemonic, ins.Operands)
+}
+
+// AssembleError is an error produced by Assemble, carrying the source
+// line/column the problem was found at.
+type AssembleError struct {
+	Line, Column int
+	Msg          string
+}
+
+func (e *AssembleError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Msg)
+}
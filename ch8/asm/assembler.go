@@ -0,0 +1,451 @@
+package asm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Assemble turns CHIP-8 assembly source into a byte slice ready for
+// ch8.VirtualMachine.LoadBytes, using two passes: the first resolves
+// every label and EQU constant to an address/value, the second emits
+// bytes now that every forward reference can be resolved.
+func Assemble(src io.Reader) ([]byte, error) {
+	out, _, err := AssembleWithSymbols(src)
+	return out, err
+}
+
+// AssembleWithSymbols is Assemble, additionally returning the label
+// and EQU symbol table resolved along the way, so a caller (e.g. a
+// debugger wanting to set a breakpoint by label) can map a name back
+// to the address it was assembled to.
+func AssembleWithSymbols(src io.Reader) ([]byte, map[string]uint16, error) {
+	lines, err := readLines(src)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	symbols := map[string]uint16{}
+	var stmts []statement
+
+	addr := uint16(0x200)
+	for lineNo, raw := range lines {
+		stmt, err := parseLine(raw, lineNo+1)
+		if err != nil {
+			return nil, nil, err
+		}
+		if stmt == nil {
+			continue
+		}
+
+		if stmt.label != "" {
+			symbols[stmt.label] = addr
+		}
+		if stmt.equName != "" {
+			v, err := parseNumber(stmt.equValue)
+			if err != nil {
+				return nil, nil, &AssembleError{lineNo + 1, 1, "bad EQU value: " + stmt.equValue}
+			}
+			symbols[stmt.equName] = v
+			continue
+		}
+		if stmt.mnemonic == "" {
+			continue
+		}
+
+		stmt.addr = addr
+		stmts = append(stmts, *stmt)
+		addr += uint16(stmt.size())
+	}
+
+	var out []byte
+	for _, stmt := range stmts {
+		bytes, err := stmt.emit(symbols)
+		if err != nil {
+			return nil, nil, err
+		}
+		out = append(out, bytes...)
+	}
+
+	return out, symbols, nil
+}
+
+type statement struct {
+	line     int
+	label    string
+	equName  string
+	equValue string
+	mnemonic string
+	operands []string
+	data     []uint16 // .db/.dw values (raw, pre-resolved)
+	addr     uint16
+}
+
+func (s statement) size() int {
+	if s.mnemonic == ".db" {
+		return len(s.data)
+	}
+	if s.mnemonic == ".dw" {
+		return len(s.data) * 2
+	}
+	return 2
+}
+
+func (s statement) emit(symbols map[string]uint16) ([]byte, error) {
+	if s.mnemonic == ".db" {
+		out := make([]byte, len(s.data))
+		for i, v := range s.data {
+			out[i] = byte(v)
+		}
+		return out, nil
+	}
+	if s.mnemonic == ".dw" {
+		out := make([]byte, 0, len(s.data)*2)
+		for _, v := range s.data {
+			out = append(out, byte(v>>8), byte(v))
+		}
+		return out, nil
+	}
+
+	opcode, err := s.assembleOpcode(symbols)
+	if err != nil {
+		return nil, err
+	}
+	return []byte{byte(opcode >> 8), byte(opcode)}, nil
+}
+
+func (s statement) operandValue(i int, symbols map[string]uint16) (uint16, error) {
+	if i >= len(s.operands) {
+		return 0, &AssembleError{s.line, 1, "missing operand"}
+	}
+
+	operand := s.operands[i]
+	if v, ok := symbols[operand]; ok {
+		return v, nil
+	}
+	return parseNumber(operand)
+}
+
+func register(operand string) (uint16, bool) {
+	operand = strings.ToUpper(strings.TrimSpace(operand))
+	if len(operand) < 2 || operand[0] != 'V' {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(operand[1:], 16, 8)
+	if err != nil {
+		return 0, false
+	}
+	return uint16(v), true
+}
+
+func (s statement) assembleOpcode(symbols map[string]uint16) (uint16, error) {
+	mnemonic := strings.ToUpper(s.mnemonic)
+	ops := s.operands
+
+	reg := func(i int) (uint16, error) {
+		if i >= len(ops) {
+			return 0, &AssembleError{s.line, 1, "missing register operand"}
+		}
+		v, ok := register(ops[i])
+		if !ok {
+			return 0, &AssembleError{s.line, 1, "expected register, got " + ops[i]}
+		}
+		return v, nil
+	}
+
+	switch mnemonic {
+	case "CLS":
+		return 0x00e0, nil
+	case "RET":
+		return 0x00ee, nil
+	case "JP":
+		if len(ops) == 2 {
+			nnn, err := s.operandValue(1, symbols)
+			if err != nil {
+				return 0, err
+			}
+			return 0xb000 | (nnn & 0xfff), nil
+		}
+		nnn, err := s.operandValue(0, symbols)
+		if err != nil {
+			return 0, err
+		}
+		return 0x1000 | (nnn & 0xfff), nil
+	case "CALL":
+		nnn, err := s.operandValue(0, symbols)
+		if err != nil {
+			return 0, err
+		}
+		return 0x2000 | (nnn & 0xfff), nil
+	case "SE", "SNE":
+		x, err := reg(0)
+		if err != nil {
+			return 0, err
+		}
+		base := uint16(0x3000)
+		if mnemonic == "SNE" {
+			base = 0x4000
+		}
+		if y, ok := register(ops[1]); ok {
+			if mnemonic == "SE" {
+				base = 0x5000
+			} else {
+				base = 0x9000
+			}
+			return base | x<<8 | y<<4, nil
+		}
+		kk, err := s.operandValue(1, symbols)
+		if err != nil {
+			return 0, err
+		}
+		return base | x<<8 | (kk & 0xff), nil
+	case "ADD":
+		if x, ok := register(ops[0]); ok {
+			if y, ok := register(ops[1]); ok {
+				return 0x8004 | x<<8 | y<<4, nil
+			}
+			kk, err := s.operandValue(1, symbols)
+			if err != nil {
+				return 0, err
+			}
+			return 0x7000 | x<<8 | (kk & 0xff), nil
+		}
+		if strings.ToUpper(ops[0]) == "I" {
+			x, err := reg(1)
+			if err != nil {
+				return 0, err
+			}
+			return 0xf01e | x<<8, nil
+		}
+		return 0, &AssembleError{s.line, 1, "invalid ADD operands"}
+	case "OR", "AND", "XOR", "SUB", "SUBN":
+		x, err := reg(0)
+		if err != nil {
+			return 0, err
+		}
+		y, err := reg(1)
+		if err != nil {
+			return 0, err
+		}
+		n := map[string]uint16{"OR": 1, "AND": 2, "XOR": 3, "SUB": 5, "SUBN": 7}[mnemonic]
+		return 0x8000 | x<<8 | y<<4 | n, nil
+	case "SHR", "SHL":
+		x, err := reg(0)
+		if err != nil {
+			return 0, err
+		}
+		y := uint16(0)
+		if len(ops) > 1 {
+			y, _ = register(ops[1])
+		}
+		n := uint16(0x6)
+		if mnemonic == "SHL" {
+			n = 0xe
+		}
+		return 0x8000 | x<<8 | y<<4 | n, nil
+	case "RND":
+		x, err := reg(0)
+		if err != nil {
+			return 0, err
+		}
+		kk, err := s.operandValue(1, symbols)
+		if err != nil {
+			return 0, err
+		}
+		return 0xc000 | x<<8 | (kk & 0xff), nil
+	case "DRW":
+		x, err := reg(0)
+		if err != nil {
+			return 0, err
+		}
+		y, err := reg(1)
+		if err != nil {
+			return 0, err
+		}
+		n, err := s.operandValue(2, symbols)
+		if err != nil {
+			return 0, err
+		}
+		return 0xd000 | x<<8 | y<<4 | (n & 0xf), nil
+	case "SKP":
+		x, err := reg(0)
+		if err != nil {
+			return 0, err
+		}
+		return 0xe09e | x<<8, nil
+	case "SKNP":
+		x, err := reg(0)
+		if err != nil {
+			return 0, err
+		}
+		return 0xe0a1 | x<<8, nil
+	case "LD":
+		return s.assembleLD(symbols)
+	}
+
+	return 0, &AssembleError{s.line, 1, "unknown mnemonic: " + s.mnemonic}
+}
+
+func (s statement) assembleLD(symbols map[string]uint16) (uint16, error) {
+	ops := s.operands
+	if len(ops) != 2 {
+		return 0, &AssembleError{s.line, 1, "LD requires two operands"}
+	}
+
+	dst, src := strings.TrimSpace(ops[0]), strings.TrimSpace(ops[1])
+	upperSrc := strings.ToUpper(src)
+	upperDst := strings.ToUpper(dst)
+
+	if upperDst == "I" {
+		nnn, err := s.operandValue(1, symbols)
+		if err != nil {
+			return 0, err
+		}
+		return 0xa000 | (nnn & 0xfff), nil
+	}
+	if upperDst == "DT" {
+		x, _ := register(src)
+		return 0xf015 | x<<8, nil
+	}
+	if upperDst == "ST" {
+		x, _ := register(src)
+		return 0xf018 | x<<8, nil
+	}
+	if upperDst == "F" {
+		x, _ := register(src)
+		return 0xf029 | x<<8, nil
+	}
+	if upperDst == "B" {
+		x, _ := register(src)
+		return 0xf033 | x<<8, nil
+	}
+	if upperDst == "[I]" {
+		x, _ := register(src)
+		return 0xf055 | x<<8, nil
+	}
+
+	x, ok := register(dst)
+	if !ok {
+		return 0, &AssembleError{s.line, 1, "invalid LD destination: " + dst}
+	}
+
+	switch {
+	case upperSrc == "DT":
+		return 0xf007 | x<<8, nil
+	case upperSrc == "K":
+		return 0xf00a | x<<8, nil
+	case upperSrc == "[I]":
+		return 0xf065 | x<<8, nil
+	}
+	if y, ok := register(src); ok {
+		return 0x8000 | x<<8 | y<<4, nil
+	}
+
+	kk, err := s.operandValue(1, symbols)
+	if err != nil {
+		return 0, err
+	}
+	return 0x6000 | x<<8 | (kk & 0xff), nil
+}
+
+func readLines(src io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(src)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+func parseLine(raw string, lineNo int) (*statement, error) {
+	line := raw
+	if i := strings.IndexByte(line, ';'); i >= 0 {
+		line = line[:i]
+	}
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil, nil
+	}
+
+	stmt := &statement{line: lineNo}
+
+	if i := strings.IndexByte(line, ':'); i >= 0 && !strings.ContainsAny(line[:i], " \t") {
+		stmt.label = line[:i]
+		line = strings.TrimSpace(line[i+1:])
+		if line == "" {
+			return stmt, nil
+		}
+	}
+
+	fields := strings.SplitN(line, " ", 2)
+	mnemonic := fields[0]
+	rest := ""
+	if len(fields) > 1 {
+		rest = strings.TrimSpace(fields[1])
+	}
+
+	if len(rest) >= 3 && strings.EqualFold(rest[:3], "equ") {
+		stmt.equName = mnemonic
+		stmt.equValue = strings.TrimSpace(rest[3:])
+		return stmt, nil
+	}
+
+	switch strings.ToLower(mnemonic) {
+	case ".db", "db":
+		stmt.mnemonic = ".db"
+		for _, operand := range splitOperands(rest) {
+			v, err := parseNumber(operand)
+			if err != nil {
+				return nil, &AssembleError{lineNo, 1, "bad .db value: " + operand}
+			}
+			stmt.data = append(stmt.data, v)
+		}
+		return stmt, nil
+	case ".dw", "dw":
+		stmt.mnemonic = ".dw"
+		for _, operand := range splitOperands(rest) {
+			v, err := parseNumber(operand)
+			if err != nil {
+				return nil, &AssembleError{lineNo, 1, "bad .dw value: " + operand}
+			}
+			stmt.data = append(stmt.data, v)
+		}
+		return stmt, nil
+	}
+
+	stmt.mnemonic = mnemonic
+	stmt.operands = splitOperands(rest)
+	return stmt, nil
+}
+
+func splitOperands(s string) []string {
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	out := make([]string, len(parts))
+	for i, p := range parts {
+		out[i] = strings.TrimSpace(p)
+	}
+	return out
+}
+
+func parseNumber(s string) (uint16, error) {
+	s = strings.TrimSpace(s)
+	base := 10
+	switch {
+	case strings.HasPrefix(s, "0x"), strings.HasPrefix(s, "0X"):
+		s, base = s[2:], 16
+	case strings.HasPrefix(s, "$"):
+		s, base = s[1:], 16
+	}
+
+	v, err := strconv.ParseUint(s, base, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number: %s", s)
+	}
+	return uint16(v), nil
+}
+
@@ -0,0 +1,231 @@
+package asm
+
+import "fmt"
+
+// Disassemble decodes a loaded CHIP-8 ROM into a labeled mnemonic
+// listing, starting from org (typically ch8.ProgramStartAddress).
+//
+// Code is distinguished from data via reachability analysis: starting
+// at org, every JP/CALL/skip successor is followed, and only the
+// addresses reached that way are decoded as instructions. Anything
+// else in bytes is left out of the listing, the same way a real
+// CHIP-8 CPU never executes data mixed into program memory unless a
+// jump lands on it.
+func Disassemble(bytes []byte, org uint16) ([]Instruction, error) {
+	mem := make(map[uint16]uint8, len(bytes))
+	for i, b := range bytes {
+		mem[org+uint16(i)] = b
+	}
+
+	labels := map[uint16]string{}
+	reachable := map[uint16]bool{}
+	queue := []uint16{org}
+
+	for len(queue) > 0 {
+		addr := queue[0]
+		queue = queue[1:]
+
+		if reachable[addr] {
+			continue
+		}
+		if _, ok := mem[addr]; !ok {
+			continue
+		}
+		if _, ok := mem[addr+1]; !ok {
+			continue
+		}
+
+		reachable[addr] = true
+
+		opcode := uint16(mem[addr])<<8 | uint16(mem[addr+1])
+		next := addr + 2
+
+		switch opcode >> 12 {
+		case 0x1, 0x2:
+			target := opcode & 0xfff
+			labels[target] = fmt.Sprintf("L%03X", target)
+			queue = append(queue, target)
+			if opcode>>12 == 0x2 {
+				queue = append(queue, next)
+			}
+		case 0x3, 0x4, 0x5, 0x9, 0xe:
+			queue = append(queue, next, next+2)
+		case 0xb:
+			// Bnnn's effective target depends on a register value at
+			// runtime; fall through to the next instruction so the
+			// listing still covers the common case of a jump table
+			// immediately following.
+			queue = append(queue, next)
+		case 0x0:
+			if opcode == 0x00ee {
+				continue
+			}
+			queue = append(queue, next)
+		default:
+			queue = append(queue, next)
+		}
+	}
+
+	var addrs []uint16
+	for addr := range reachable {
+		addrs = append(addrs, addr)
+	}
+	sortUint16s(addrs)
+
+	instructions := make([]Instruction, 0, len(addrs))
+	for _, addr := range addrs {
+		opcode := uint16(mem[addr])<<8 | uint16(mem[addr+1])
+		ins := decode(addr, opcode)
+		ins.Label = labels[addr]
+		instructions = append(instructions, ins)
+	}
+
+	return instructions, nil
+}
+
+// DisassembleWindow linearly decodes every two bytes between start
+// and end (exclusive) as an instruction, without Disassemble's
+// reachability analysis. Meant for a debugger overlay showing the
+// instructions around the current PC, where the surrounding bytes
+// aren't necessarily a jump/call target and so wouldn't otherwise be
+// reached.
+func DisassembleWindow(mem []uint8, start, end uint16) []Instruction {
+	if int(end) > len(mem) {
+		end = uint16(len(mem))
+	}
+
+	instructions := make([]Instruction, 0, (end-start)/2)
+	for addr := start; addr+1 < end; addr += 2 {
+		opcode := uint16(mem[addr])<<8 | uint16(mem[addr+1])
+		instructions = append(instructions, decode(addr, opcode))
+	}
+
+	return instructions
+}
+
+func sortUint16s(s []uint16) {
+	for i := 1; i < len(s); i++ {
+		for j := i; j > 0 && s[j-1] > s[j]; j-- {
+			s[j-1], s[j] = s[j], s[j-1]
+		}
+	}
+}
+
+func decode(addr, opcode uint16) Instruction {
+	x := (opcode >> 8) & 0xf
+	y := (opcode >> 4) & 0xf
+	n := opcode & 0xf
+	kk := opcode & 0xff
+	nnn := opcode & 0xfff
+
+	ins := Instruction{Addr: addr, Opcode: opcode}
+
+	switch opcode >> 12 {
+	case 0x0:
+		switch opcode {
+		case 0x00e0:
+			ins.Mnemonic = "CLS"
+		case 0x00ee:
+			ins.Mnemonic = "RET"
+		default:
+			ins.Mnemonic = "SYS"
+			ins.Operands = fmt.Sprintf("0x%03X", nnn)
+		}
+	case 0x1:
+		ins.Mnemonic = "JP"
+		ins.Operands = fmt.Sprintf("0x%03X", nnn)
+	case 0x2:
+		ins.Mnemonic = "CALL"
+		ins.Operands = fmt.Sprintf("0x%03X", nnn)
+	case 0x3:
+		ins.Mnemonic = "SE"
+		ins.Operands = fmt.Sprintf("V%X, 0x%02X", x, kk)
+	case 0x4:
+		ins.Mnemonic = "SNE"
+		ins.Operands = fmt.Sprintf("V%X, 0x%02X", x, kk)
+	case 0x5:
+		ins.Mnemonic = "SE"
+		ins.Operands = fmt.Sprintf("V%X, V%X", x, y)
+	case 0x6:
+		ins.Mnemonic = "LD"
+		ins.Operands = fmt.Sprintf("V%X, 0x%02X", x, kk)
+	case 0x7:
+		ins.Mnemonic = "ADD"
+		ins.Operands = fmt.Sprintf("V%X, 0x%02X", x, kk)
+	case 0x8:
+		switch n {
+		case 0x0:
+			ins.Mnemonic, ins.Operands = "LD", fmt.Sprintf("V%X, V%X", x, y)
+		case 0x1:
+			ins.Mnemonic, ins.Operands = "OR", fmt.Sprintf("V%X, V%X", x, y)
+		case 0x2:
+			ins.Mnemonic, ins.Operands = "AND", fmt.Sprintf("V%X, V%X", x, y)
+		case 0x3:
+			ins.Mnemonic, ins.Operands = "XOR", fmt.Sprintf("V%X, V%X", x, y)
+		case 0x4:
+			ins.Mnemonic, ins.Operands = "ADD", fmt.Sprintf("V%X, V%X", x, y)
+		case 0x5:
+			ins.Mnemonic, ins.Operands = "SUB", fmt.Sprintf("V%X, V%X", x, y)
+		case 0x6:
+			ins.Mnemonic, ins.Operands = "SHR", fmt.Sprintf("V%X, V%X", x, y)
+		case 0x7:
+			ins.Mnemonic, ins.Operands = "SUBN", fmt.Sprintf("V%X, V%X", x, y)
+		case 0xe:
+			ins.Mnemonic, ins.Operands = "SHL", fmt.Sprintf("V%X, V%X", x, y)
+		default:
+			ins.Mnemonic = "DATA"
+			ins.Operands = fmt.Sprintf("0x%04X", opcode)
+		}
+	case 0x9:
+		ins.Mnemonic = "SNE"
+		ins.Operands = fmt.Sprintf("V%X, V%X", x, y)
+	case 0xa:
+		ins.Mnemonic = "LD"
+		ins.Operands = fmt.Sprintf("I, 0x%03X", nnn)
+	case 0xb:
+		ins.Mnemonic = "JP"
+		ins.Operands = fmt.Sprintf("V0, 0x%03X", nnn)
+	case 0xc:
+		ins.Mnemonic = "RND"
+		ins.Operands = fmt.Sprintf("V%X, 0x%02X", x, kk)
+	case 0xd:
+		ins.Mnemonic = "DRW"
+		ins.Operands = fmt.Sprintf("V%X, V%X, 0x%X", x, y, n)
+	case 0xe:
+		switch kk {
+		case 0x9e:
+			ins.Mnemonic, ins.Operands = "SKP", fmt.Sprintf("V%X", x)
+		case 0xa1:
+			ins.Mnemonic, ins.Operands = "SKNP", fmt.Sprintf("V%X", x)
+		default:
+			ins.Mnemonic = "DATA"
+			ins.Operands = fmt.Sprintf("0x%04X", opcode)
+		}
+	case 0xf:
+		switch kk {
+		case 0x07:
+			ins.Mnemonic, ins.Operands = "LD", fmt.Sprintf("V%X, DT", x)
+		case 0x0a:
+			ins.Mnemonic, ins.Operands = "LD", fmt.Sprintf("V%X, K", x)
+		case 0x15:
+			ins.Mnemonic, ins.Operands = "LD", fmt.Sprintf("DT, V%X", x)
+		case 0x18:
+			ins.Mnemonic, ins.Operands = "LD", fmt.Sprintf("ST, V%X", x)
+		case 0x1e:
+			ins.Mnemonic, ins.Operands = "ADD", fmt.Sprintf("I, V%X", x)
+		case 0x29:
+			ins.Mnemonic, ins.Operands = "LD", fmt.Sprintf("F, V%X", x)
+		case 0x33:
+			ins.Mnemonic, ins.Operands = "LD", fmt.Sprintf("B, V%X", x)
+		case 0x55:
+			ins.Mnemonic, ins.Operands = "LD", fmt.Sprintf("[I], V%X", x)
+		case 0x65:
+			ins.Mnemonic, ins.Operands = "LD", fmt.Sprintf("V%X, [I]", x)
+		default:
+			ins.Mnemonic = "DATA"
+			ins.Operands = fmt.Sprintf("0x%04X", opcode)
+		}
+	}
+
+	return ins
+}
@@ -35,6 +35,22 @@ const (
 	// DisplayHeight is the height (in pixels) of the CHIP-8 display.
 	DisplayHeight = 0x20
 
+	// HiResDisplayWidth is the width (in pixels) of the SUPER-CHIP and
+	// XO-CHIP hi-res display.
+	HiResDisplayWidth = 0x80
+
+	// HiResDisplayHeight is the height (in pixels) of the SUPER-CHIP
+	// and XO-CHIP hi-res display.
+	HiResDisplayHeight = 0x40
+
+	// NumberOfPlanes is the number of display bitplanes a virtual
+	// machine running in XO-CHIP mode can draw to.
+	NumberOfPlanes = 0x2
+
+	// BigFontSize is the number of bytes in a SUPER-CHIP/XO-CHIP
+	// built-in big-font character.
+	BigFontSize = 0xa
+
 	// NumberOfKeys is the number of keys in the CHIP-8 keyboard.
 	NumberOfKeys = 0x10
 
@@ -57,4 +73,12 @@ const (
 	// ProgramMemorySize is the total amount of memory available for
 	// CHIP-8 programs.
 	ProgramMemorySize = MemorySize - ProgramStartAddress
+
+	// RewindInterval is the number of CPU cycles between snapshots
+	// kept in a rewind buffer.
+	RewindInterval = 0x3c
+
+	// RewindCapacity is the number of snapshots a rewind buffer keeps
+	// before it starts discarding the oldest ones.
+	RewindCapacity = 0x12c
 )
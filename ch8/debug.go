@@ -0,0 +1,138 @@
+package ch8
+
+// State is a serializable capture of a virtual machine's execution
+// state, used for save-states, rewinding, and the debug protocol's
+// snapshot command.
+type State struct {
+	I            uint16
+	SP           uint8
+	PC           uint16
+	DT           uint8
+	ST           uint8
+	Opcode       uint16
+	V            [NumberOfRegisters]uint8
+	Stack        [MaxStackDepth]uint16
+	Memory       [MemorySize]uint8
+	Keys         [NumberOfKeys]bool
+	Display      [NumberOfPlanes][HiResDisplayHeight][HiResDisplayWidth]bool
+	Mode         Mode
+	Width        int
+	Height       int
+	AudioPattern [0x10]uint8
+	Pitch        uint8
+	RNGSeed      int64
+}
+
+// SetBreakpoint halts Continue just before the instruction at addr
+// executes.
+func (vm *VirtualMachine) SetBreakpoint(addr uint16) {
+	if vm.breakpoints == nil {
+		vm.breakpoints = map[uint16]bool{}
+	}
+	vm.breakpoints[addr] = true
+}
+
+// ClearBreakpoint removes a previously set breakpoint.
+func (vm *VirtualMachine) ClearBreakpoint(addr uint16) {
+	delete(vm.breakpoints, addr)
+}
+
+// AtBreakpoint reports whether the instruction about to be fetched is
+// a breakpoint.
+func (vm *VirtualMachine) AtBreakpoint() bool {
+	return vm.breakpoints[vm.PC]
+}
+
+// StepInstruction executes exactly one CPU cycle, ignoring any
+// breakpoint at the current PC.
+func (vm *VirtualMachine) StepInstruction() error {
+	return vm.RunCycle()
+}
+
+// StepOver executes one instruction, and if it was a CALL (2NNN),
+// keeps running until the matching RET brings the stack back to its
+// depth before the call.
+func (vm *VirtualMachine) StepOver() error {
+	isCall := vm.decodeAt(vm.PC)>>12 == 0x2
+	targetDepth := vm.SP
+
+	if err := vm.StepInstruction(); err != nil {
+		return err
+	}
+	if !isCall {
+		return nil
+	}
+
+	for vm.SP > targetDepth {
+		if err := vm.StepInstruction(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Continue runs instructions until a breakpoint is hit or an error
+// occurs.
+func (vm *VirtualMachine) Continue() error {
+	for {
+		if err := vm.StepInstruction(); err != nil {
+			return err
+		}
+		if vm.AtBreakpoint() {
+			return nil
+		}
+	}
+}
+
+func (vm *VirtualMachine) decodeAt(addr uint16) uint16 {
+	return uint16(vm.Memory[addr])<<8 | uint16(vm.Memory[addr+1])
+}
+
+// Snapshot captures the virtual machine's registers, timers, stack,
+// memory, keys, display, mode, resolution, XO-CHIP audio registers and
+// RNG seed. Quirks, the selected draw planes, the RPL flags and the
+// breakpoints are not captured, and Restore reseeds Rand from the
+// seed, so RND draws start over from the beginning of that seed's
+// sequence.
+func (vm *VirtualMachine) Snapshot() State {
+	return State{
+		I:            vm.I,
+		SP:           vm.SP,
+		PC:           vm.PC,
+		DT:           vm.DT,
+		ST:           vm.ST,
+		Opcode:       vm.Opcode,
+		V:            vm.V,
+		Stack:        vm.Stack,
+		Memory:       vm.Memory,
+		Keys:         vm.Keys,
+		Display:      vm.Display,
+		Mode:         vm.Mode,
+		Width:        vm.Width,
+		Height:       vm.Height,
+		AudioPattern: vm.AudioPattern,
+		Pitch:        vm.Pitch,
+		RNGSeed:      vm.rngSeed,
+	}
+}
+
+// Restore resets the virtual machine to a previously captured state.
+func (vm *VirtualMachine) Restore(s State) {
+	vm.I = s.I
+	vm.SP = s.SP
+	vm.PC = s.PC
+	vm.DT = s.DT
+	vm.ST = s.ST
+	vm.Opcode = s.Opcode
+	vm.V = s.V
+	vm.Stack = s.Stack
+	vm.Memory = s.Memory
+	vm.Keys = s.Keys
+	vm.Display = s.Display
+	vm.Mode = s.Mode
+	vm.Width = s.Width
+	vm.Height = s.Height
+	vm.AudioPattern = s.AudioPattern
+	vm.Pitch = s.Pitch
+	vm.SeedRand(s.RNGSeed)
+}
@@ -0,0 +1,172 @@
+// Package debug implements a disassembler and step-debugger overlay
+// for ch8.VirtualMachine, building on the breakpoint and step
+// primitives it already exposes.
+package debug
+
+import (
+	"fmt"
+
+	"github.com/kevhlee/chip8/ch8"
+	"github.com/kevhlee/chip8/ch8/asm"
+)
+
+// ErrUnknownLabel is returned by AddBreakpointAtLabel when the symbol
+// table has no entry for the requested label.
+type ErrUnknownLabel string
+
+func (e ErrUnknownLabel) Error() string {
+	return fmt.Sprintf("unknown label: %s", string(e))
+}
+
+// Instruction is a disassembled CHIP-8 instruction, re-exported from
+// ch8/asm so callers of this package don't need to import it too.
+type Instruction = asm.Instruction
+
+// Disassemble decodes the instructions between start and end
+// (exclusive) in vm's memory, without reachability analysis, so a
+// debugger overlay can show the code around the current PC even when
+// it wasn't reached by a jump/call.
+func Disassemble(vm *ch8.VirtualMachine, start, end uint16) []Instruction {
+	return asm.DisassembleWindow(vm.Memory[:], start, end)
+}
+
+// Watchpoint reports that the byte at Addr changed from Old to New
+// since it was last checked.
+type Watchpoint struct {
+	Addr     uint16
+	Old, New uint8
+}
+
+// Debugger wraps a VirtualMachine with the bookkeeping a step-debugger
+// needs beyond the breakpoint/step primitives ch8.VirtualMachine
+// already exposes: memory watches and call-stack-aware step-out.
+type Debugger struct {
+	VM *ch8.VirtualMachine
+
+	watches map[uint16]uint8
+}
+
+// NewDebugger wraps vm for debugging.
+func NewDebugger(vm *ch8.VirtualMachine) *Debugger {
+	return &Debugger{VM: vm, watches: map[uint16]uint8{}}
+}
+
+// AddBreakpoint halts VM.Continue and the emulator's run loop just
+// before the instruction at addr executes. StepInto, StepOver and
+// StepOut do not stop at breakpoints.
+func (d *Debugger) AddBreakpoint(addr uint16) {
+	d.VM.SetBreakpoint(addr)
+}
+
+// RemoveBreakpoint removes a previously added breakpoint.
+func (d *Debugger) RemoveBreakpoint(addr uint16) {
+	d.VM.ClearBreakpoint(addr)
+}
+
+// AddBreakpointAtLabel resolves label in symbols (as returned by
+// asm.AssembleWithSymbols or Emulator.Symbols) and sets a breakpoint
+// there, so ROMs assembled from source can be debugged by label
+// instead of raw address.
+func (d *Debugger) AddBreakpointAtLabel(symbols map[string]uint16, label string) error {
+	addr, ok := symbols[label]
+	if !ok {
+		return ErrUnknownLabel(label)
+	}
+	d.AddBreakpoint(addr)
+	return nil
+}
+
+// AddWatch starts watching addr, recording its current value as the
+// baseline future changes are reported against.
+func (d *Debugger) AddWatch(addr uint16) {
+	d.watches[addr] = d.VM.Memory[addr]
+}
+
+// RemoveWatch stops watching addr.
+func (d *Debugger) RemoveWatch(addr uint16) {
+	delete(d.watches, addr)
+}
+
+// checkWatches returns a Watchpoint for every watched address whose
+// value changed since it was last checked, resetting the baseline.
+func (d *Debugger) checkWatches() []Watchpoint {
+	var hits []Watchpoint
+	for addr, old := range d.watches {
+		if n := d.VM.Memory[addr]; n != old {
+			hits = append(hits, Watchpoint{Addr: addr, Old: old, New: n})
+			d.watches[addr] = n
+		}
+	}
+	return hits
+}
+
+// StepInto executes exactly one instruction and reports any watches
+// that changed as a result.
+func (d *Debugger) StepInto() ([]Watchpoint, error) {
+	if err := d.VM.StepInstruction(); err != nil {
+		return nil, err
+	}
+	return d.checkWatches(), nil
+}
+
+// StepOver executes one instruction, running through an entire
+// CALL/RET pair if it was a call, and reports any watches that
+// changed.
+func (d *Debugger) StepOver() ([]Watchpoint, error) {
+	if err := d.VM.StepOver(); err != nil {
+		return nil, err
+	}
+	return d.checkWatches(), nil
+}
+
+// StepOut runs the virtual machine until the call stack unwinds below
+// the depth it was at when StepOut was called, i.e. until the current
+// function returns.
+func (d *Debugger) StepOut() ([]Watchpoint, error) {
+	if d.VM.SP == 0 {
+		return d.StepInto()
+	}
+	targetDepth := d.VM.SP - 1
+
+	for d.VM.SP > targetDepth {
+		if err := d.VM.StepInstruction(); err != nil {
+			return nil, err
+		}
+	}
+	return d.checkWatches(), nil
+}
+
+// Registers formats V0-VF, I, SP, PC, DT, and ST for a debugger pane.
+func (d *Debugger) Registers() string {
+	vm := d.VM
+	s := fmt.Sprintf("PC=%03X I=%03X SP=%X DT=%02X ST=%02X\n", vm.PC, vm.I, vm.SP, vm.DT, vm.ST)
+	for i, v := range vm.V {
+		s += fmt.Sprintf("V%X=%02X ", i, v)
+	}
+	return s
+}
+
+// MemoryHex formats n bytes of memory starting at addr as a single
+// hex dump line, for a debugger's memory pane.
+func (d *Debugger) MemoryHex(addr uint16, n int) string {
+	s := fmt.Sprintf("%03X:", addr)
+	for i := 0; i < n && int(addr)+i < len(d.VM.Memory); i++ {
+		s += fmt.Sprintf(" %02X", d.VM.Memory[int(addr)+i])
+	}
+	return s
+}
+
+// DisassembleAroundPC returns the instructions within window bytes
+// before and after the current PC, for a debugger overlay's
+// disassembly pane.
+func (d *Debugger) DisassembleAroundPC(window int) []Instruction {
+	start := int(d.VM.PC) - window
+	if start < 0 {
+		start = 0
+	}
+	end := int(d.VM.PC) + window
+	if end > len(d.VM.Memory) {
+		end = len(d.VM.Memory)
+	}
+	return Disassemble(d.VM, uint16(start), uint16(end))
+}
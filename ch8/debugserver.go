@@ -0,0 +1,106 @@
+package ch8
+
+import (
+	"bufio"
+	"encoding/json"
+	"log"
+	"net"
+)
+
+// debugRequest is a single line of the newline-delimited JSON debug
+// protocol exposed on EmulatorOptions.DebugAddr.
+type debugRequest struct {
+	Cmd  string `json:"cmd"`
+	Addr uint16 `json:"addr,omitempty"`
+}
+
+type debugResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+	State *State `json:"state,omitempty"`
+}
+
+// debugCmd carries a connection's request into the VM-owning goroutine
+// via Emulator.debugCmds, so debug commands never touch the virtual
+// machine concurrently with startVM's own cycle loop.
+type debugCmd struct {
+	req    debugRequest
+	respCh chan debugResponse
+}
+
+// serveDebug listens on addr and lets external UIs (VSCode, a custom
+// TUI) drive the emulator's debugger surface over JSON-over-TCP.
+//
+// One command is accepted per line: {"cmd":"break","addr":512},
+// {"cmd":"clear","addr":512}, {"cmd":"step"}, {"cmd":"stepOver"},
+// {"cmd":"continue"}, {"cmd":"snapshot"}.
+func (emu *Emulator) serveDebug(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go emu.handleDebugConn(conn)
+		}
+	}()
+
+	return nil
+}
+
+func (emu *Emulator) handleDebugConn(conn net.Conn) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	encoder := json.NewEncoder(conn)
+
+	for scanner.Scan() {
+		var req debugRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			encoder.Encode(debugResponse{Error: err.Error()})
+			continue
+		}
+
+		respCh := make(chan debugResponse, 1)
+		emu.debugCmds <- debugCmd{req: req, respCh: respCh}
+		resp := <-respCh
+
+		if err := encoder.Encode(resp); err != nil {
+			log.Println(err)
+			return
+		}
+	}
+}
+
+func (emu *Emulator) handleDebugRequest(req debugRequest) debugResponse {
+	switch req.Cmd {
+	case "break":
+		emu.vm.SetBreakpoint(req.Addr)
+	case "clear":
+		emu.vm.ClearBreakpoint(req.Addr)
+	case "step":
+		if err := emu.vm.StepInstruction(); err != nil {
+			return debugResponse{Error: err.Error()}
+		}
+	case "stepOver":
+		if err := emu.vm.StepOver(); err != nil {
+			return debugResponse{Error: err.Error()}
+		}
+	case "continue":
+		if err := emu.vm.Continue(); err != nil {
+			return debugResponse{Error: err.Error()}
+		}
+	case "snapshot":
+		state := emu.vm.Snapshot()
+		return debugResponse{OK: true, State: &state}
+	default:
+		return debugResponse{Error: "unknown command: " + req.Cmd}
+	}
+
+	return debugResponse{OK: true}
+}
@@ -2,83 +2,77 @@ package ch8
 
 import (
 	"errors"
-	"fmt"
-	"image/color"
-	"log"
+	"io"
 	"os"
 	"time"
 
-	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/kevhlee/chip8/ch8/asm"
 )
 
 // EmulatorOptions is a set of arguments that allows you to set
 // different options in the emulator.
 type EmulatorOptions struct {
-	// Scale is the scale factor of the CHIP-8 screen.
-	Scale int
+	// Mode selects the CHIP-8 instruction set variant the emulator
+	// runs the loaded ROM as. Defaults to ModeChip8.
+	Mode Mode
+
+	// Quirks selects the platform-specific opcode semantics the
+	// emulator runs the loaded ROM with. Defaults to DefaultQuirks.
+	Quirks Quirks
+
+	// DebugAddr, if set, exposes a JSON-over-TCP debug protocol at
+	// this address (e.g. "localhost:7890") so external tools can set
+	// breakpoints, step, and snapshot the running virtual machine.
+	DebugAddr string
 }
 
-const (
-	eventPlay      EmulatorEvent = "play"
-	eventPause     EmulatorEvent = "pause"
-	eventReset     EmulatorEvent = "reset"
-	eventTerminate EmulatorEvent = "terminate"
-)
-
-var (
-	foreground = color.White
-	background = color.Black
-
-	keyHexMap = map[ebiten.Key]uint{
-		ebiten.Key1: 0x0, ebiten.Key2: 0x1, ebiten.Key3: 0x2, ebiten.Key4: 0x3,
-		ebiten.KeyQ: 0x4, ebiten.KeyW: 0x5, ebiten.KeyE: 0x6, ebiten.KeyR: 0x7,
-		ebiten.KeyA: 0x8, ebiten.KeyS: 0x9, ebiten.KeyD: 0xa, ebiten.KeyF: 0xb,
-		ebiten.KeyZ: 0xc, ebiten.KeyX: 0xd, ebiten.KeyC: 0xe, ebiten.KeyV: 0xf,
-	}
-
-	keyEventMap = map[ebiten.Key]EmulatorEvent{
-		ebiten.KeyRightBracket: eventPause,
-		ebiten.KeyLeftBracket:  eventPlay,
-		ebiten.KeyBackslash:    eventReset,
-		ebiten.KeyEscape:       eventTerminate,
-	}
-)
-
-// Emulator is the CHIP-8 emulator.
+// Emulator is the CHIP-8 emulator. It owns the virtual machine and
+// drives it against a Frontend, which is responsible for all input
+// and presentation.
 type Emulator struct {
-	scale     int
 	vm        *VirtualMachine
-	vmChannel chan EmulatorEvent
+	frontend  Frontend
+	debugAddr string
+	debugCmds chan debugCmd
+	paused    bool
+	rewind    *rewindBuffer
+	recorder  *Recorder
+	recordOut io.Writer
+
+	// symbols is the label/EQU symbol table resolved by the most
+	// recent LoadSource call, if any. Exposed via Symbols so a
+	// debugger can set a breakpoint by label.
+	symbols map[string]uint16
 }
 
-// EmulatorEvent is an event that occurs that controls the state of the
-// emulator.
-type EmulatorEvent string
-
-// NewEmulator creates a new CHIP-8 emulator instance.
-func NewEmulator(opts EmulatorOptions) (*Emulator, error) {
-	if opts.Scale < 1 {
-		return nil, errors.New("scale factor must be positive")
+// NewEmulator creates a new CHIP-8 emulator instance driven by the
+// given Frontend.
+func NewEmulator(frontend Frontend, opts EmulatorOptions) (*Emulator, error) {
+	if frontend == nil {
+		return nil, errors.New("frontend must not be nil")
 	}
 
 	return &Emulator{
-		scale:     opts.Scale,
-		vm:        NewVirtualMachine(),
-		vmChannel: make(chan EmulatorEvent),
+		vm:        NewVirtualMachineWithQuirks(opts.Mode, opts.Quirks),
+		frontend:  frontend,
+		debugAddr: opts.DebugAddr,
+		debugCmds: make(chan debugCmd),
+		rewind:    newRewindBuffer(),
 	}, nil
 }
 
-// Start starts the emulator.
-func (emu *Emulator) Start() (err error) {
-	ebiten.SetWindowSize(DisplayWidth*emu.scale, DisplayHeight*emu.scale)
-	ebiten.SetWindowTitle("CHIP-8")
-	ebiten.SetTPS(DefaultMaxTPS)
-	ebiten.SetVsyncEnabled(true)
+// Start runs the emulator's CPU and IO loops until the frontend
+// reports ControlTerminate or the virtual machine errors out.
+func (emu *Emulator) Start() error {
+	if emu.debugAddr != "" {
+		if err := emu.serveDebug(emu.debugAddr); err != nil {
+			return err
+		}
+	}
 
 	go emu.startIO()
-	go emu.startVM()
 
-	return ebiten.RunGame(emu)
+	return emu.startVM()
 }
 
 // LoadROM reads a CHIP-8 ROM program file (*.ch8) and loads it into
@@ -88,76 +82,106 @@ func (emu *Emulator) LoadROM(filename string) error {
 	if err != nil {
 		return err
 	}
+
+	emu.vm.flagsPath = filename + ".flags"
+
 	return emu.LoadBytes(data)
 }
 
-// Update updates the state of the emulator.
-func (emu *Emulator) Update() error {
-	for key, event := range keyEventMap {
-		if ebiten.IsKeyPressed(key) {
-			if event == eventTerminate {
-				return ErrTerminated
-			}
-
-			emu.vmChannel <- event
-			return nil
-		}
+// LoadSource assembles a CHIP-8 assembly source file and loads the
+// resulting bytes into the virtual machine's memory, letting users
+// iterate on ROMs without an external toolchain. The resolved symbol
+// table is retained and can be read back with Symbols.
+func (emu *Emulator) LoadSource(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
 	}
+	defer file.Close()
 
-	for key, hex := range keyHexMap {
-		emu.vm.Keys[hex] = ebiten.IsKeyPressed(key)
+	bytes, symbols, err := asm.AssembleWithSymbols(file)
+	if err != nil {
+		return err
 	}
-	return nil
-}
 
-// Draw renders the screen of the emulator.
-func (emu *Emulator) Draw(screen *ebiten.Image) {
-	screen.Fill(background)
+	emu.symbols = symbols
 
-	for y := 0; y < DisplayHeight; y++ {
-		for x := 0; x < DisplayWidth; x++ {
-			if emu.vm.Display[y][x] {
-				screen.Set(x, y, foreground)
-			}
-		}
-	}
-
-	ebiten.SetWindowTitle(fmt.Sprintf("CHIP-8 | FPS: %.2f", ebiten.ActualFPS()))
+	return emu.LoadBytes(bytes)
 }
 
-// Layout returns the resolution of the emulator's screen.
-func (emu *Emulator) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return DisplayWidth, DisplayHeight
+// Symbols returns the label/EQU symbol table resolved by the most
+// recent LoadSource call, or nil if LoadSource hasn't been called.
+func (emu *Emulator) Symbols() map[string]uint16 {
+	return emu.symbols
 }
 
-func (emu *Emulator) startVM() {
-	pause := false
+func (emu *Emulator) startVM() error {
+	ticker := time.NewTicker(DefaultHertzVM)
+	defer ticker.Stop()
 
-	for range time.Tick(DefaultHertzVM) {
+	for {
 		select {
-		case event := <-emu.vmChannel:
-			switch event {
-			case eventPlay:
-				pause = false
-			case eventPause:
-				pause = true
-			case eventReset:
-				emu.vm.Reset()
+		case cmd := <-emu.debugCmds:
+			cmd.respCh <- emu.handleDebugRequest(cmd.req)
+			continue
+		case <-ticker.C:
+		}
+
+		switch emu.frontend.HandleControl() {
+		case ControlPlay:
+			emu.paused = false
+		case ControlPause:
+			emu.paused = true
+		case ControlReset:
+			emu.vm.Reset()
+		case ControlRewind:
+			if s, ok := emu.rewind.Pop(); ok {
+				emu.vm.Restore(s)
 			}
-		default:
-			if pause {
-				continue
+		case ControlStep:
+			if emu.paused {
+				emu.vm.Keys = emu.frontend.PollKeys()
+				if err := emu.vm.RunCycle(); err != nil {
+					return err
+				}
+				emu.rewind.Tick(emu.vm)
+				if emu.recorder != nil {
+					emu.recorder.Tick(emu.vm)
+				}
+				emu.frontend.PresentDisplay(emu.vm.displayFrame())
 			}
+		case ControlTerminate:
+			return ErrTerminated
+		}
 
-			if err := emu.vm.RunCycle(); err != nil {
-				log.Println(err)
-			}
+		if emu.paused {
+			continue
 		}
+
+		emu.vm.Keys = emu.frontend.PollKeys()
+
+		if emu.vm.AtBreakpoint() {
+			emu.paused = true
+			continue
+		}
+
+		if err := emu.vm.RunCycle(); err != nil {
+			return err
+		}
+
+		emu.rewind.Tick(emu.vm)
+		if emu.recorder != nil {
+			emu.recorder.Tick(emu.vm)
+		}
+
+		emu.frontend.PresentDisplay(emu.vm.displayFrame())
 	}
 }
 
 func (emu *Emulator) startIO() {
 	for range time.Tick(DefaultHertzIO) {
 		emu.vm.UpdateTimers()
+		emu.frontend.SetAudioPattern(emu.vm.AudioPattern, emu.vm.Pitch)
+		emu.frontend.Beep(emu.vm.ST > 0)
 	}
 }
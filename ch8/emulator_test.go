@@ -0,0 +1,46 @@
+package ch8_test
+
+import (
+	"testing"
+
+	"github.com/kevhlee/chip8/ch8"
+	"github.com/kevhlee/chip8/ch8/frontend/headless"
+)
+
+// TestHeadlessFrontendRoundTrip exercises the headless Frontend's
+// contract in isolation -- the whole point of decoupling Emulator
+// from ebiten behind the Frontend interface is that a test can drive
+// one without any graphics stack.
+func TestHeadlessFrontendRoundTrip(t *testing.T) {
+	f := headless.New()
+
+	var keys [ch8.NumberOfKeys]bool
+	keys[0xa] = true
+	f.PressKeys(keys)
+	if got := f.PollKeys(); got != keys {
+		t.Errorf("PollKeys() = %v, want %v", got, keys)
+	}
+
+	frame := [][]bool{{true, false}, {false, true}}
+	f.PresentDisplay(frame)
+	if got := f.Display(); len(got) != len(frame) {
+		t.Errorf("Display() = %v, want %v", got, frame)
+	}
+
+	f.Beep(true)
+	if !f.Beeping() {
+		t.Error("Beeping() = false after Beep(true)")
+	}
+	f.Beep(false)
+	if f.Beeping() {
+		t.Error("Beeping() = true after Beep(false)")
+	}
+
+	f.SendControl(ch8.ControlPause)
+	if got := f.HandleControl(); got != ch8.ControlPause {
+		t.Errorf("HandleControl() = %v, want %v", got, ch8.ControlPause)
+	}
+	if got := f.HandleControl(); got != ch8.ControlNone {
+		t.Errorf("HandleControl() after drain = %v, want ControlNone", got)
+	}
+}
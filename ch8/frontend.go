@@ -0,0 +1,59 @@
+package ch8
+
+// ControlEvent is a frontend-agnostic control signal a Frontend
+// reports back to the Emulator, driven by whatever hotkey or command
+// the frontend exposes (a keybinding, a terminal command, an RPC).
+type ControlEvent string
+
+const (
+	// ControlNone means no control event occurred.
+	ControlNone ControlEvent = ""
+
+	// ControlPlay resumes a paused emulator.
+	ControlPlay ControlEvent = "play"
+
+	// ControlPause pauses the emulator.
+	ControlPause ControlEvent = "pause"
+
+	// ControlReset resets the virtual machine to its initial state.
+	ControlReset ControlEvent = "reset"
+
+	// ControlRewind restores the virtual machine to its most recent
+	// rewind snapshot.
+	ControlRewind ControlEvent = "rewind"
+
+	// ControlStep, while paused, executes exactly one CPU cycle and
+	// stays paused.
+	ControlStep ControlEvent = "step"
+
+	// ControlTerminate stops the emulator.
+	ControlTerminate ControlEvent = "terminate"
+)
+
+// Frontend is the interface an Emulator uses to read input and
+// present output, so the CHIP-8 core can run against a GUI, a
+// terminal, a headless test harness, or any other presentation layer
+// without depending on it directly.
+type Frontend interface {
+	// PollKeys returns the current pressed state of the 16 CHIP-8
+	// keys.
+	PollKeys() [NumberOfKeys]bool
+
+	// PresentDisplay renders a frame of the CHIP-8 display, already
+	// flattened to a single width x height grid of lit pixels.
+	PresentDisplay(display [][]bool)
+
+	// Beep turns the frontend's audio tone on or off.
+	Beep(on bool)
+
+	// SetAudioPattern updates the XO-CHIP audio pattern buffer (Fx02)
+	// and playback pitch register (Fx3A) the next Beep(true) should
+	// play back, in place of the plain tone. A zero pattern means no
+	// ROM has executed Fx02 yet; frontends should fall back to their
+	// plain tone in that case.
+	SetAudioPattern(pattern [0x10]uint8, pitch uint8)
+
+	// HandleControl returns any pending control event, or
+	// ControlNone if there isn't one.
+	HandleControl() ControlEvent
+}
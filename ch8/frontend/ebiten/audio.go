@@ -0,0 +1,154 @@
+package ebiten
+
+import (
+	"io"
+	"math"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+const (
+	// DefaultAudioFrequency is the default frequency (in Hz) of the
+	// square-wave tone played while the beeper is on.
+	DefaultAudioFrequency = 440
+
+	// DefaultAudioSampleRate is the default sample rate (in Hz) of the
+	// generated beeper tone.
+	DefaultAudioSampleRate = 44100
+)
+
+// xoChipNeutralPitch is the Fx3A pitch register value that plays an
+// XO-CHIP audio pattern at the spec's neutral 4000Hz rate.
+const xoChipNeutralPitch = 64
+
+// xoChipPitchHz converts an Fx3A pitch register value to the playback
+// rate (in Hz) it selects, per the XO-CHIP spec.
+func xoChipPitchHz(pitch uint8) float64 {
+	return 4000 * math.Pow(2, (float64(pitch)-xoChipNeutralPitch)/48)
+}
+
+// beeper plays a continuous square wave through ebiten/audio,
+// starting and stopping it as the Frontend's Beep is toggled. Once
+// SetPattern has been called with a non-zero pattern, it plays that
+// XO-CHIP audio pattern buffer instead of the plain tone.
+type beeper struct {
+	ctx        *audio.Context
+	player     *audio.Player
+	reader     *waveReader
+	sampleRate float64
+	muted      bool
+}
+
+// newBeeper creates a beeper that loops a square wave at freq Hz,
+// sampled at sampleRate Hz.
+func newBeeper(freq, sampleRate int, muted bool) (*beeper, error) {
+	b := &beeper{
+		ctx:        audio.NewContext(sampleRate),
+		sampleRate: float64(sampleRate),
+		muted:      muted,
+	}
+
+	b.reader = newWaveReader(float64(freq), float64(sampleRate))
+
+	player, err := b.ctx.NewPlayer(b.reader)
+	if err != nil {
+		return nil, err
+	}
+	player.SetVolume(1.0)
+
+	b.player = player
+	return b, nil
+}
+
+// Beep starts or stops the tone.
+func (b *beeper) Beep(on bool) {
+	if b.muted || b.player == nil {
+		return
+	}
+
+	if on {
+		if !b.player.IsPlaying() {
+			b.player.Play()
+		}
+		return
+	}
+
+	b.player.Pause()
+}
+
+// SetPattern updates the XO-CHIP audio pattern buffer and playback
+// pitch played once pattern is no longer all zero. A zero pattern
+// falls back to the plain square-wave tone.
+func (b *beeper) SetPattern(pattern [0x10]uint8, pitchHz float64) {
+	b.reader.setPattern(pattern, pitchHz)
+}
+
+// waveReader generates an infinite 16-bit stereo square wave,
+// suitable for ebiten/audio.NewPlayer: either a fixed-frequency tone,
+// or, once a non-zero pattern is set, the XO-CHIP audio pattern buffer
+// read as a 128-bit bitstream at the configured pitch.
+type waveReader struct {
+	freq       float64
+	sampleRate float64
+	pos        float64
+
+	mu      sync.Mutex
+	pattern [0x10]uint8
+	pitchHz float64
+}
+
+func newWaveReader(freq, sampleRate float64) *waveReader {
+	return &waveReader{freq: freq, sampleRate: sampleRate}
+}
+
+func (r *waveReader) setPattern(pattern [0x10]uint8, pitchHz float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pattern = pattern
+	r.pitchHz = pitchHz
+}
+
+// Read implements io.Reader, producing little-endian 16-bit stereo
+// PCM samples.
+func (r *waveReader) Read(p []byte) (int, error) {
+	const bytesPerFrame = 4 // 16-bit stereo
+
+	r.mu.Lock()
+	pattern := r.pattern
+	pitchHz := r.pitchHz
+	r.mu.Unlock()
+
+	hasPattern := pattern != [0x10]uint8{}
+
+	n := len(p) / bytesPerFrame * bytesPerFrame
+	period := r.sampleRate / r.freq
+	bitRate := pitchHz // pattern bits played per second
+
+	for i := 0; i < n; i += bytesPerFrame {
+		var on bool
+
+		if hasPattern {
+			bit := int(r.pos) % (len(pattern) * 8)
+			on = pattern[bit/8]&(1<<(7-uint(bit%8))) != 0
+			r.pos += bitRate / r.sampleRate
+		} else {
+			on = math.Mod(r.pos, period) < period/2
+			r.pos++
+		}
+
+		var sample int16 = 0x4fff
+		if !on {
+			sample = -0x4fff
+		}
+
+		p[i] = byte(sample)
+		p[i+1] = byte(sample >> 8)
+		p[i+2] = byte(sample)
+		p[i+3] = byte(sample >> 8)
+	}
+
+	return n, nil
+}
+
+var _ io.Reader = (*waveReader)(nil)
@@ -0,0 +1,192 @@
+// Package ebiten implements a ch8.Frontend backed by an ebiten
+// window, for desktop use.
+package ebiten
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/kevhlee/chip8/ch8"
+)
+
+var (
+	foreground = color.White
+	background = color.Black
+
+	keyHexMap = map[ebiten.Key]uint{
+		ebiten.Key1: 0x0, ebiten.Key2: 0x1, ebiten.Key3: 0x2, ebiten.Key4: 0x3,
+		ebiten.KeyQ: 0x4, ebiten.KeyW: 0x5, ebiten.KeyE: 0x6, ebiten.KeyR: 0x7,
+		ebiten.KeyA: 0x8, ebiten.KeyS: 0x9, ebiten.KeyD: 0xa, ebiten.KeyF: 0xb,
+		ebiten.KeyZ: 0xc, ebiten.KeyX: 0xd, ebiten.KeyC: 0xe, ebiten.KeyV: 0xf,
+	}
+
+	keyControlMap = map[ebiten.Key]ch8.ControlEvent{
+		ebiten.KeyRightBracket: ch8.ControlPause,
+		ebiten.KeyLeftBracket:  ch8.ControlPlay,
+		ebiten.KeyBackslash:    ch8.ControlReset,
+		ebiten.KeyMinus:        ch8.ControlRewind,
+		ebiten.KeyEqual:        ch8.ControlStep,
+		ebiten.KeyEscape:       ch8.ControlTerminate,
+	}
+)
+
+// Options configures a Frontend.
+type Options struct {
+	// Scale is the scale factor of the CHIP-8 screen.
+	Scale int
+
+	// AudioFrequency is the frequency (in Hz) of the beeper tone.
+	// Defaults to DefaultAudioFrequency.
+	AudioFrequency int
+
+	// AudioSampleRate is the sample rate (in Hz) used to generate the
+	// beeper tone. Defaults to DefaultAudioSampleRate.
+	AudioSampleRate int
+
+	// Muted disables the beeper entirely.
+	Muted bool
+}
+
+// Frontend is a ch8.Frontend backed by an ebiten window. ebiten owns
+// the main loop via RunGame, so Frontend buffers the latest
+// input/display state behind a mutex and exchanges it with the
+// Emulator's own goroutine-driven loop.
+type Frontend struct {
+	scale int
+	audio *beeper
+
+	mu      sync.Mutex
+	keys    [ch8.NumberOfKeys]bool
+	display [][]bool
+	control ch8.ControlEvent
+}
+
+// New creates an ebiten-backed Frontend.
+func New(opts Options) (*Frontend, error) {
+	if opts.Scale < 1 {
+		opts.Scale = ch8.DefaultScale
+	}
+
+	freq := opts.AudioFrequency
+	if freq <= 0 {
+		freq = DefaultAudioFrequency
+	}
+
+	sampleRate := opts.AudioSampleRate
+	if sampleRate <= 0 {
+		sampleRate = DefaultAudioSampleRate
+	}
+
+	beep, err := newBeeper(freq, sampleRate, opts.Muted)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Frontend{scale: opts.Scale, audio: beep}, nil
+}
+
+// Run opens the ebiten window and blocks until it's closed, driving
+// emu's CPU/IO loop in a background goroutine.
+func (f *Frontend) Run(emu *ch8.Emulator) error {
+	ebiten.SetWindowSize(ch8.HiResDisplayWidth*f.scale, ch8.HiResDisplayHeight*f.scale)
+	ebiten.SetWindowTitle("CHIP-8")
+	ebiten.SetTPS(ch8.DefaultMaxTPS)
+	ebiten.SetVsyncEnabled(true)
+
+	go func() {
+		if err := emu.Start(); err != nil && err != ch8.ErrTerminated {
+			log.Println(err)
+		}
+	}()
+
+	return ebiten.RunGame(f)
+}
+
+// Update implements ebiten.Game.
+func (f *Frontend) Update() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for key, event := range keyControlMap {
+		if ebiten.IsKeyPressed(key) {
+			f.control = event
+			if event == ch8.ControlTerminate {
+				return ch8.ErrTerminated
+			}
+			break
+		}
+	}
+
+	for key, hex := range keyHexMap {
+		f.keys[hex] = ebiten.IsKeyPressed(key)
+	}
+	return nil
+}
+
+// Draw implements ebiten.Game.
+func (f *Frontend) Draw(screen *ebiten.Image) {
+	f.mu.Lock()
+	display := f.display
+	f.mu.Unlock()
+
+	screen.Fill(background)
+	for y, row := range display {
+		for x, on := range row {
+			if on {
+				screen.Set(x, y, foreground)
+			}
+		}
+	}
+
+	ebiten.SetWindowTitle(fmt.Sprintf("CHIP-8 | FPS: %.2f", ebiten.ActualFPS()))
+}
+
+// Layout implements ebiten.Game.
+func (f *Frontend) Layout(outsideWidth, outsideHeight int) (int, int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.display) == 0 {
+		return ch8.DisplayWidth, ch8.DisplayHeight
+	}
+	return len(f.display[0]), len(f.display)
+}
+
+// PollKeys implements ch8.Frontend.
+func (f *Frontend) PollKeys() [ch8.NumberOfKeys]bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.keys
+}
+
+// PresentDisplay implements ch8.Frontend.
+func (f *Frontend) PresentDisplay(display [][]bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.display = display
+}
+
+// Beep implements ch8.Frontend.
+func (f *Frontend) Beep(on bool) {
+	f.audio.Beep(on)
+}
+
+// SetAudioPattern implements ch8.Frontend.
+func (f *Frontend) SetAudioPattern(pattern [0x10]uint8, pitch uint8) {
+	f.audio.SetPattern(pattern, xoChipPitchHz(pitch))
+}
+
+// HandleControl implements ch8.Frontend.
+func (f *Frontend) HandleControl() ch8.ControlEvent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	event := f.control
+	f.control = ch8.ControlNone
+	return event
+}
+
+var _ ch8.Frontend = (*Frontend)(nil)
@@ -0,0 +1,131 @@
+// Package headless implements a ch8.Frontend that does no rendering
+// or input polling at all, for driving an Emulator from tests or CI
+// without a graphics stack.
+package headless
+
+import (
+	"hash/fnv"
+	"sync"
+
+	"github.com/kevhlee/chip8/ch8"
+)
+
+// Frontend is a ch8.Frontend with no real input or output: keys are
+// set programmatically via PressKeys, control events are queued with
+// SendControl, and the most recently presented frame can be read back
+// with Display for golden-frame tests.
+type Frontend struct {
+	mu      sync.Mutex
+	keys    [ch8.NumberOfKeys]bool
+	display [][]bool
+	beeping bool
+	pattern [0x10]uint8
+	pitch   uint8
+	control ch8.ControlEvent
+}
+
+// New creates a headless Frontend.
+func New() *Frontend {
+	return &Frontend{}
+}
+
+// PressKeys sets the pressed state of the 16 CHIP-8 keys, as if a
+// user were holding them down.
+func (f *Frontend) PressKeys(keys [ch8.NumberOfKeys]bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.keys = keys
+}
+
+// SendControl queues a control event to be returned on the next
+// HandleControl call.
+func (f *Frontend) SendControl(event ch8.ControlEvent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.control = event
+}
+
+// Display returns the most recently presented frame.
+func (f *Frontend) Display() [][]bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.display
+}
+
+// DisplayHash hashes the most recently presented frame, so a
+// regression test can assert on a known-good hash at a fixed cycle
+// count instead of comparing the whole framebuffer.
+func (f *Frontend) DisplayHash() uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	h := fnv.New64a()
+	for _, row := range f.display {
+		for _, lit := range row {
+			if lit {
+				h.Write([]byte{1})
+			} else {
+				h.Write([]byte{0})
+			}
+		}
+	}
+	return h.Sum64()
+}
+
+// Beeping reports whether the emulator last asked for the tone to be
+// on.
+func (f *Frontend) Beeping() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.beeping
+}
+
+// AudioPattern returns the XO-CHIP audio pattern buffer and pitch
+// register most recently set by SetAudioPattern, for tests asserting
+// on Fx02/Fx3A.
+func (f *Frontend) AudioPattern() ([0x10]uint8, uint8) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.pattern, f.pitch
+}
+
+// PollKeys implements ch8.Frontend.
+func (f *Frontend) PollKeys() [ch8.NumberOfKeys]bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.keys
+}
+
+// PresentDisplay implements ch8.Frontend.
+func (f *Frontend) PresentDisplay(display [][]bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.display = display
+}
+
+// Beep implements ch8.Frontend.
+func (f *Frontend) Beep(on bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.beeping = on
+}
+
+// SetAudioPattern implements ch8.Frontend.
+func (f *Frontend) SetAudioPattern(pattern [0x10]uint8, pitch uint8) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pattern = pattern
+	f.pitch = pitch
+}
+
+// HandleControl implements ch8.Frontend.
+func (f *Frontend) HandleControl() ch8.ControlEvent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	event := f.control
+	f.control = ch8.ControlNone
+	return event
+}
+
+var _ ch8.Frontend = (*Frontend)(nil)
@@ -0,0 +1,142 @@
+// Package terminal implements a ch8.Frontend that draws the CHIP-8
+// display to a TTY using Unicode half-block characters, packing two
+// pixel rows into each line of text the way terminal NES/GB emulators
+// (e.g. nesemu1) squeeze a framebuffer into a character grid.
+package terminal
+
+import (
+	"bufio"
+	"io"
+	"sync"
+
+	"github.com/kevhlee/chip8/ch8"
+)
+
+var keyRuneMap = map[rune]uint{
+	'1': 0x1, '2': 0x2, '3': 0x3, '4': 0xc,
+	'q': 0x4, 'w': 0x5, 'e': 0x6, 'r': 0xd,
+	'a': 0x7, 's': 0x8, 'd': 0x9, 'f': 0xe,
+	'z': 0xa, 'x': 0x0, 'c': 0xb, 'v': 0xf,
+}
+
+// Frontend is a ch8.Frontend that renders to an io.Writer (normally
+// os.Stdout) and reads single-key input from an io.Reader (normally
+// os.Stdin), which the caller is expected to have put into raw mode.
+type Frontend struct {
+	out *bufio.Writer
+	in  *bufio.Reader
+
+	mu      sync.Mutex
+	keys    [ch8.NumberOfKeys]bool
+	control ch8.ControlEvent
+}
+
+// New creates a terminal Frontend that writes frames to out and
+// reads keypresses from in.
+func New(out io.Writer, in io.Reader) *Frontend {
+	f := &Frontend{
+		out: bufio.NewWriter(out),
+		in:  bufio.NewReader(in),
+	}
+	go f.readInput()
+	return f
+}
+
+// readInput blocks reading one byte at a time from in, translating
+// recognized keys into a brief key-down pulse. A raw terminal has no
+// notion of key-up, so a pressed key reads as held for a single
+// emulator cycle rather than until released.
+func (f *Frontend) readInput() {
+	for {
+		r, _, err := f.in.ReadRune()
+		if err != nil {
+			return
+		}
+
+		f.mu.Lock()
+		switch r {
+		case '\x1b':
+			f.control = ch8.ControlTerminate
+		case 'p':
+			f.control = ch8.ControlPause
+		case 'o':
+			f.control = ch8.ControlPlay
+		case 'b':
+			f.control = ch8.ControlRewind
+		case 'n':
+			f.control = ch8.ControlStep
+		default:
+			if hex, ok := keyRuneMap[r]; ok {
+				f.keys[hex] = true
+			}
+		}
+		f.mu.Unlock()
+	}
+}
+
+// PollKeys implements ch8.Frontend. Since a raw TTY can't report key
+// release, every key reported pressed is cleared immediately after
+// being read.
+func (f *Frontend) PollKeys() [ch8.NumberOfKeys]bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	keys := f.keys
+	f.keys = [ch8.NumberOfKeys]bool{}
+	return keys
+}
+
+// PresentDisplay implements ch8.Frontend, drawing two pixel rows per
+// line of text using the upper/lower half-block characters.
+func (f *Frontend) PresentDisplay(display [][]bool) {
+	f.out.WriteString("\x1b[H")
+
+	for y := 0; y < len(display); y += 2 {
+		for x := range display[y] {
+			top := display[y][x]
+
+			var bottom bool
+			if y+1 < len(display) {
+				bottom = display[y+1][x]
+			}
+
+			switch {
+			case top && bottom:
+				f.out.WriteRune('█')
+			case top:
+				f.out.WriteRune('▀')
+			case bottom:
+				f.out.WriteRune('▄')
+			default:
+				f.out.WriteRune(' ')
+			}
+		}
+		f.out.WriteString("\x1b[K\n")
+	}
+
+	f.out.Flush()
+}
+
+// Beep implements ch8.Frontend by ringing the terminal bell.
+func (f *Frontend) Beep(on bool) {
+	if on {
+		f.out.WriteRune('\a')
+		f.out.Flush()
+	}
+}
+
+// SetAudioPattern implements ch8.Frontend. A TTY bell can't play back
+// a waveform, so the XO-CHIP pattern/pitch are ignored.
+func (f *Frontend) SetAudioPattern(pattern [0x10]uint8, pitch uint8) {}
+
+// HandleControl implements ch8.Frontend.
+func (f *Frontend) HandleControl() ch8.ControlEvent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	event := f.control
+	f.control = ch8.ControlNone
+	return event
+}
+
+var _ ch8.Frontend = (*Frontend)(nil)
@@ -0,0 +1,31 @@
+package ch8
+
+// Mode selects which CHIP-8 instruction set variant the virtual
+// machine interprets opcodes as.
+type Mode int
+
+const (
+	// ModeChip8 is the original COSMAC VIP CHIP-8 instruction set.
+	ModeChip8 Mode = iota
+
+	// ModeSuperChip is the SUPER-CHIP 1.1 instruction set, adding a
+	// 128x64 hi-res mode, scrolling, and big sprites.
+	ModeSuperChip
+
+	// ModeXOChip is the XO-CHIP instruction set, layering a second
+	// display plane, a wider I load, and an audio pattern buffer on
+	// top of SUPER-CHIP.
+	ModeXOChip
+)
+
+// String returns the human-readable name of the mode.
+func (m Mode) String() string {
+	switch m {
+	case ModeSuperChip:
+		return "schip"
+	case ModeXOChip:
+		return "xochip"
+	default:
+		return "chip8"
+	}
+}
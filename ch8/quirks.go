@@ -0,0 +1,70 @@
+package ch8
+
+// Quirks captures the opcode-semantics disagreements between CHIP-8
+// platforms. Historical ROMs were written against one interpreter's
+// behavior, and running them against a different set of quirks
+// silently miscompiles them (wrong shifts, clobbered registers,
+// sprites drawn in the wrong place).
+type Quirks struct {
+	// ShiftUsesVy makes 8xy6/8xyE shift Vy into Vx before shifting,
+	// instead of shifting Vx in place.
+	ShiftUsesVy bool
+
+	// LoadStoreIncrementsI makes Fx55/Fx65 leave I set to I+x+1
+	// instead of restoring it to its original value.
+	LoadStoreIncrementsI bool
+
+	// JumpUsesVx makes Bxnn jump to Vx+nnn instead of V0+nnn.
+	JumpUsesVx bool
+
+	// LogicResetsVF makes 8xy1/8xy2/8xy3 reset VF to 0.
+	LogicResetsVF bool
+
+	// DisplayWait makes Dxyn block until the next timer tick (60Hz)
+	// before drawing, matching the original COSMAC VIP's vertical
+	// blank synchronization.
+	DisplayWait bool
+
+	// SpriteClipping clips sprites at the edge of the display instead
+	// of wrapping them around to the opposite edge.
+	SpriteClipping bool
+
+	// MemoryIncrementByX, together with LoadStoreIncrementsI, makes
+	// Fx55/Fx65 increment I by x instead of x+1. It has no effect on
+	// its own.
+	MemoryIncrementByX bool
+}
+
+// QuirksCOSMACVIP is the quirks profile of the original COSMAC VIP
+// CHIP-8 interpreter.
+var QuirksCOSMACVIP = Quirks{
+	ShiftUsesVy:          true,
+	LoadStoreIncrementsI: true,
+	JumpUsesVx:           false,
+	DisplayWait:          true,
+	SpriteClipping:       true,
+}
+
+// QuirksCHIP48 is the quirks profile of the CHIP-48 interpreter,
+// notably diverging from the COSMAC VIP on shifts and load/store.
+var QuirksCHIP48 = Quirks{
+	ShiftUsesVy:          false,
+	LoadStoreIncrementsI: false,
+	JumpUsesVx:           true,
+	SpriteClipping:       true,
+}
+
+// QuirksSuperChip is the quirks profile of SUPER-CHIP 1.1.
+var QuirksSuperChip = Quirks{
+	ShiftUsesVy:          false,
+	LoadStoreIncrementsI: false,
+	JumpUsesVx:           true,
+	SpriteClipping:       true,
+}
+
+// DefaultQuirks is the quirks profile used when none is supplied. It
+// is the zero-value Quirks, matching this VM's pre-existing,
+// hardcoded behavior (Vx-only shifts, V0-relative jumps, wrapping
+// sprites, I left untouched by Fx55/Fx65) so existing callers of
+// NewVirtualMachine see no change in behavior.
+var DefaultQuirks = Quirks{}
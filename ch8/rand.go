@@ -0,0 +1,29 @@
+package ch8
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Rand is the random source backing the 0xC (RND) opcode. The default
+// implementation is a seeded *rand.Rand, but callers can inject their
+// own (e.g. a fixed sequence) for reproducible test ROMs.
+type Rand interface {
+	Uint32() uint32
+}
+
+// SeedRand reseeds the virtual machine's random source from seed,
+// replacing whatever Rand was previously set. This is what makes
+// Recorder/Replay reproduce a run's RNG draws exactly.
+func (vm *VirtualMachine) SeedRand(seed int64) {
+	vm.Rand = rand.New(rand.NewSource(seed))
+	vm.seedRNG(seed)
+}
+
+// newDefaultRand seeds a Rand from the current time, the same
+// non-reproducible behavior the hardcoded math/rand.Uint32() call
+// used to have.
+func newDefaultRand() (Rand, int64) {
+	seed := time.Now().UnixNano()
+	return rand.New(rand.NewSource(seed)), seed
+}
@@ -0,0 +1,53 @@
+package ch8_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/kevhlee/chip8/ch8"
+	"github.com/kevhlee/chip8/ch8/frontend/headless"
+)
+
+// wantDisplayHash is the golden headless.DisplayHash of drawDigitROM's
+// display after cycleCount cycles, pinning down RunCycle, the sprite
+// drawing opcodes, and DisplayHash itself against a regression.
+const wantDisplayHash = 0x35d51ba17427bf3
+
+const cycleCount = 4
+
+// drawDigitROM loads V0 with 0, points I at the built-in "0" sprite,
+// draws it at (0,0), then jumps to itself so the display is stable
+// for any cycle count of 4 or more.
+var drawDigitROM = []byte{
+	0x60, 0x00, // LD V0, 0
+	0xF0, 0x29, // LD F, V0
+	0xD0, 0x05, // DRW V0, V0, 5
+	0x12, 0x06, // JP 0x206
+}
+
+func TestDisplayHashRegression(t *testing.T) {
+	vm := ch8.NewVirtualMachineWithRand(ch8.ModeChip8, ch8.DefaultQuirks, rand.New(rand.NewSource(1)))
+	copy(vm.Memory[0x200:], drawDigitROM)
+
+	for i := 0; i < cycleCount; i++ {
+		if err := vm.RunCycle(); err != nil {
+			t.Fatalf("RunCycle: %v", err)
+		}
+	}
+
+	frame := make([][]bool, vm.Height)
+	for y := range frame {
+		row := make([]bool, vm.Width)
+		for x := range row {
+			row[x] = vm.Display[0][y][x] || vm.Display[1][y][x]
+		}
+		frame[y] = row
+	}
+
+	f := headless.New()
+	f.PresentDisplay(frame)
+
+	if got := f.DisplayHash(); got != wantDisplayHash {
+		t.Errorf("DisplayHash() = %#x, want %#x", got, wantDisplayHash)
+	}
+}
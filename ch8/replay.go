@@ -0,0 +1,110 @@
+package ch8
+
+import (
+	"encoding/gob"
+	"io"
+)
+
+// KeyEvent is a single key state change captured during a recording,
+// tagged with the cycle it happened on.
+type KeyEvent struct {
+	Cycle   uint64
+	Key     uint8
+	Pressed bool
+}
+
+// Recording is the seed, mode, and per-cycle input log captured by a
+// Recorder — enough to replay a run bit-for-bit.
+type Recording struct {
+	Seed        int64
+	Mode        Mode
+	TotalCycles uint64
+	Events      []KeyEvent
+}
+
+// Recorder captures a deterministic trace of a running virtual
+// machine: its RNG seed plus every key state change and the cycle it
+// happened on, so a bug report can be replayed exactly instead of
+// "it happens sometimes".
+type Recorder struct {
+	recording Recording
+	cycle     uint64
+	lastKeys  [NumberOfKeys]bool
+}
+
+// NewRecorder creates a Recorder against vm's current seed and mode.
+func NewRecorder(vm *VirtualMachine) *Recorder {
+	return &Recorder{
+		recording: Recording{Seed: vm.rngSeed, Mode: vm.Mode},
+		lastKeys:  vm.Keys,
+	}
+}
+
+// Tick records any key state changes since the last call and
+// advances the cycle counter. Call this once per RunCycle.
+func (r *Recorder) Tick(vm *VirtualMachine) {
+	for i, pressed := range vm.Keys {
+		if pressed != r.lastKeys[i] {
+			r.recording.Events = append(r.recording.Events, KeyEvent{
+				Cycle:   r.cycle,
+				Key:     uint8(i),
+				Pressed: pressed,
+			})
+			r.lastKeys[i] = pressed
+		}
+	}
+	r.cycle++
+	r.recording.TotalCycles = r.cycle
+}
+
+// Encode writes the captured recording to w.
+func (r *Recorder) Encode(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(r.recording)
+}
+
+// Record starts capturing a deterministic recording of the
+// emulator's virtual machine. Call StopRecording to flush it to w.
+func (emu *Emulator) Record(w io.Writer) {
+	emu.recorder = NewRecorder(emu.vm)
+	emu.recordOut = w
+}
+
+// StopRecording flushes the current recording to the writer given to
+// Record and stops capturing further cycles.
+func (emu *Emulator) StopRecording() error {
+	if emu.recorder == nil {
+		return nil
+	}
+
+	err := emu.recorder.Encode(emu.recordOut)
+	emu.recorder = nil
+	return err
+}
+
+// Replay resets the virtual machine, restores the recorded seed and
+// mode, and drives RunCycle for the recorded number of cycles,
+// replaying key events at their original cycle boundaries.
+func (emu *Emulator) Replay(r io.Reader) error {
+	var recording Recording
+	if err := gob.NewDecoder(r).Decode(&recording); err != nil {
+		return err
+	}
+
+	emu.vm.Reset()
+	emu.vm.SeedRand(recording.Seed)
+	emu.vm.Mode = recording.Mode
+
+	events := recording.Events
+	for cycle := uint64(0); cycle < recording.TotalCycles; cycle++ {
+		for len(events) > 0 && events[0].Cycle == cycle {
+			emu.vm.Keys[events[0].Key] = events[0].Pressed
+			events = events[1:]
+		}
+
+		if err := emu.vm.RunCycle(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package ch8
+
+// rewindBuffer is a ring buffer of virtual machine snapshots, taken
+// every RewindInterval cycles, that backs the emulator's rewind
+// hotkey. Older snapshots beyond RewindCapacity are discarded.
+type rewindBuffer struct {
+	states []State
+	cycle  uint64
+}
+
+func newRewindBuffer() *rewindBuffer {
+	return &rewindBuffer{states: make([]State, 0, RewindCapacity)}
+}
+
+// Tick takes a snapshot every RewindInterval cycles, dropping the
+// oldest snapshot once the buffer is at capacity. Call this once per
+// RunCycle.
+func (b *rewindBuffer) Tick(vm *VirtualMachine) {
+	b.cycle++
+	if b.cycle%RewindInterval != 0 {
+		return
+	}
+
+	if len(b.states) == cap(b.states) {
+		copy(b.states, b.states[1:])
+		b.states = b.states[:len(b.states)-1]
+	}
+	b.states = append(b.states, vm.Snapshot())
+}
+
+// Pop removes and returns the most recent snapshot, along with
+// whether one was available.
+func (b *rewindBuffer) Pop() (State, bool) {
+	if len(b.states) == 0 {
+		return State{}, false
+	}
+
+	s := b.states[len(b.states)-1]
+	b.states = b.states[:len(b.states)-1]
+	return s, true
+}
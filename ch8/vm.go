@@ -1,7 +1,7 @@
 package ch8
 
 import (
-	"math/rand"
+	"os"
 )
 
 var (
@@ -23,6 +23,31 @@ var (
 		0xf0, 0x80, 0xf0, 0x80, 0xf0, // E
 		0xf0, 0x80, 0xf0, 0x80, 0x80, // F
 	}
+
+	// bigFonts is the SUPER-CHIP/XO-CHIP built-in 10-byte-per-glyph
+	// big font, placed in memory right after the regular fonts.
+	bigFonts = []uint8{
+		0x3c, 0x7e, 0xe7, 0xc3, 0xc3, 0xc3, 0xc3, 0xe7, 0x7e, 0x3c, // 0
+		0x18, 0x38, 0x58, 0x18, 0x18, 0x18, 0x18, 0x18, 0x18, 0x3c, // 1
+		0x3e, 0x7f, 0xc3, 0x06, 0x0c, 0x18, 0x30, 0x60, 0xff, 0xff, // 2
+		0x3c, 0x7e, 0xc3, 0x03, 0x0e, 0x0e, 0x03, 0xc3, 0x7e, 0x3c, // 3
+		0x06, 0x0e, 0x1e, 0x36, 0x66, 0xc6, 0xff, 0xff, 0x06, 0x06, // 4
+		0xff, 0xff, 0xc0, 0xc0, 0xfc, 0xfe, 0x03, 0xc3, 0x7e, 0x3c, // 5
+		0x3e, 0x7c, 0xc0, 0xc0, 0xfc, 0xfe, 0xc3, 0xc3, 0x7e, 0x3c, // 6
+		0xff, 0xff, 0x03, 0x06, 0x0c, 0x18, 0x30, 0x30, 0x30, 0x30, // 7
+		0x3c, 0x7e, 0xc3, 0xc3, 0x7e, 0x7e, 0xc3, 0xc3, 0x7e, 0x3c, // 8
+		0x3c, 0x7e, 0xc3, 0xc3, 0x7f, 0x3f, 0x03, 0x03, 0x3e, 0x7c, // 9
+		0x18, 0x3c, 0x66, 0xc3, 0xc3, 0xff, 0xff, 0xc3, 0xc3, 0xc3, // A
+		0xfc, 0xfe, 0xc3, 0xc3, 0xfe, 0xfc, 0xc3, 0xc3, 0xfe, 0xfc, // B
+		0x3c, 0x7e, 0xc3, 0xc0, 0xc0, 0xc0, 0xc0, 0xc3, 0x7e, 0x3c, // C
+		0xfc, 0xfe, 0xc3, 0xc3, 0xc3, 0xc3, 0xc3, 0xc3, 0xfe, 0xfc, // D
+		0xff, 0xff, 0xc0, 0xc0, 0xfc, 0xfc, 0xc0, 0xc0, 0xff, 0xff, // E
+		0xff, 0xff, 0xc0, 0xc0, 0xfc, 0xfc, 0xc0, 0xc0, 0xc0, 0xc0, // F
+	}
+
+	// bigFontOffset is the memory address where bigFonts is loaded,
+	// right after the regular font set.
+	bigFontOffset = len(fonts)
 )
 
 // VirtualMachine is the CHIP-8 virtual machine.
@@ -37,23 +62,115 @@ type VirtualMachine struct {
 	Stack   [MaxStackDepth]uint16
 	Memory  [MemorySize]uint8
 	Keys    [NumberOfKeys]bool
-	Display [DisplayHeight][DisplayWidth]bool
+	Display [NumberOfPlanes][HiResDisplayHeight][HiResDisplayWidth]bool
+
+	// Mode selects the instruction set variant this virtual machine
+	// interprets opcodes as.
+	Mode Mode
+
+	// Quirks selects which platform-specific opcode semantics this
+	// virtual machine honors.
+	Quirks Quirks
+
+	// Width and Height are the dimensions (in pixels) of the active
+	// display area. They default to the base resolution and grow to
+	// HiResDisplayWidth/HiResDisplayHeight once hi-res mode (00FF) is
+	// toggled on.
+	Width  int
+	Height int
+
+	// plane is the bitmask (bit N selects plane N) of display planes
+	// that draw/scroll opcodes currently affect. Only meaningful in
+	// ModeXOChip; CHIP-8 and SUPER-CHIP always draw to plane 0.
+	plane uint8
+
+	// rplFlags is the SUPER-CHIP "flag" storage backing Fx75/Fx85,
+	// persisted to disk via SaveFlags/LoadFlags.
+	rplFlags [NumberOfRegisters]uint8
+
+	// flagsPath is the file RPL flags are persisted to. It defaults
+	// to a name derived from the loaded ROM in LoadROM.
+	flagsPath string
+
+	// AudioPattern is the 16-byte XO-CHIP audio pattern buffer loaded
+	// by Fx02 (see F002 in the Fx table).
+	AudioPattern [0x10]uint8
+
+	// Pitch is the XO-CHIP playback pitch register set by Fx3A,
+	// controlling the rate AudioPattern is played back at. 64 is the
+	// neutral value (4000Hz).
+	Pitch uint8
+
+	// VBlank, when non-nil, is pulsed by UpdateTimers once per 60Hz
+	// tick so executeOp0xD can block on it to honor the DisplayWait
+	// quirk, matching the COSMAC VIP's vertical-blank synchronization.
+	VBlank chan struct{}
+
+	// breakpoints is the set of addresses Continue halts before
+	// executing.
+	breakpoints map[uint16]bool
+
+	// rngSeed is the seed behind the random source backing 0xC
+	// (RND), tracked so Snapshot/Restore can reproduce it exactly.
+	rngSeed int64
+
+	// Rand is the random source backing 0xC (RND). Defaults to a
+	// *rand.Rand seeded from the current time; inject a custom Rand
+	// (or call SeedRand) for reproducible runs.
+	Rand Rand
+}
+
+// seedRNG records the seed behind the virtual machine's random source
+// so it can be captured by Snapshot. It does not reseed Rand itself.
+func (vm *VirtualMachine) seedRNG(seed int64) {
+	vm.rngSeed = seed
 }
 
-// NewVirtualMachine creates new CHIP-8 virtual machine instance.
-func NewVirtualMachine() *VirtualMachine {
+// NewVirtualMachine creates new CHIP-8 virtual machine instance running
+// in the given mode with the default quirks profile.
+func NewVirtualMachine(mode Mode) *VirtualMachine {
+	return NewVirtualMachineWithQuirks(mode, DefaultQuirks)
+}
+
+// NewVirtualMachineWithQuirks creates a new CHIP-8 virtual machine
+// instance running in the given mode, honoring the given quirks
+// profile instead of the default one.
+func NewVirtualMachineWithQuirks(mode Mode, quirks Quirks) *VirtualMachine {
 	vm := &VirtualMachine{
 		PC:      ProgramStartAddress,
 		Stack:   [MaxStackDepth]uint16{},
 		V:       [NumberOfRegisters]uint8{},
 		Keys:    [NumberOfKeys]bool{},
-		Display: [DisplayHeight][DisplayWidth]bool{},
 		Memory:  [MemorySize]uint8{},
+		Mode:    mode,
+		Quirks:  quirks,
+		Width:   DisplayWidth,
+		Height:  DisplayHeight,
+		plane:   0x1,
+		Pitch:   0x40,
+		VBlank:  make(chan struct{}, 1),
 	}
 
 	for i, b := range fonts {
 		vm.Memory[i] = b
 	}
+	for i, b := range bigFonts {
+		vm.Memory[bigFontOffset+i] = b
+	}
+
+	vm.Rand, vm.rngSeed = newDefaultRand()
+
+	return vm
+}
+
+// NewVirtualMachineWithRand creates a virtual machine like
+// NewVirtualMachineWithQuirks, but injecting r as its 0xC (RND) random
+// source instead of seeding one from the current time. Used by the
+// replay harness and regression tests to make RND draws reproducible
+// without going through SeedRand's *rand.Rand-backed default.
+func NewVirtualMachineWithRand(mode Mode, quirks Quirks, r Rand) *VirtualMachine {
+	vm := NewVirtualMachineWithQuirks(mode, quirks)
+	vm.Rand = r
 	return vm
 }
 
@@ -71,6 +188,13 @@ func (vm *VirtualMachine) UpdateTimers() {
 	if vm.ST > 0x00 {
 		vm.ST--
 	}
+
+	if vm.VBlank != nil {
+		select {
+		case vm.VBlank <- struct{}{}:
+		default:
+		}
+	}
 }
 
 // LoadBytes reads bytes into the virtual machine's memory.
@@ -139,13 +263,132 @@ func (vm *VirtualMachine) ClearRegisters() {
 
 // ClearDisplay clears the state of the display.
 func (vm *VirtualMachine) ClearDisplay() {
-	for y := 0; y < DisplayHeight; y++ {
-		for x := 0; x < DisplayWidth; x++ {
-			vm.Display[y][x] = false
+	for p := 0; p < NumberOfPlanes; p++ {
+		for y := 0; y < vm.Height; y++ {
+			for x := 0; x < vm.Width; x++ {
+				vm.Display[p][y][x] = false
+			}
+		}
+	}
+}
+
+// displayFrame flattens the display's bitplanes down to a single
+// Width x Height grid of lit pixels, suitable for a Frontend's
+// PresentDisplay.
+func (vm *VirtualMachine) displayFrame() [][]bool {
+	frame := make([][]bool, vm.Height)
+	for y := range frame {
+		row := make([]bool, vm.Width)
+		for x := range row {
+			row[x] = vm.Display[0][y][x] || vm.Display[1][y][x]
+		}
+		frame[y] = row
+	}
+	return frame
+}
+
+// SetHiRes switches the display between the base 64x32 resolution and
+// the SUPER-CHIP/XO-CHIP 128x64 hi-res resolution, clearing the screen
+// in the process.
+func (vm *VirtualMachine) SetHiRes(hiRes bool) {
+	if hiRes {
+		vm.Width, vm.Height = HiResDisplayWidth, HiResDisplayHeight
+	} else {
+		vm.Width, vm.Height = DisplayWidth, DisplayHeight
+	}
+	vm.ClearDisplay()
+}
+
+// scrollDown shifts every selected plane down by n rows.
+func (vm *VirtualMachine) scrollDown(n int) {
+	for p := 0; p < NumberOfPlanes; p++ {
+		if vm.plane&(1<<p) == 0 {
+			continue
+		}
+		for y := vm.Height - 1; y >= 0; y-- {
+			for x := 0; x < vm.Width; x++ {
+				if y-n >= 0 {
+					vm.Display[p][y][x] = vm.Display[p][y-n][x]
+				} else {
+					vm.Display[p][y][x] = false
+				}
+			}
+		}
+	}
+}
+
+// scrollUp shifts every selected plane up by n rows.
+func (vm *VirtualMachine) scrollUp(n int) {
+	for p := 0; p < NumberOfPlanes; p++ {
+		if vm.plane&(1<<p) == 0 {
+			continue
+		}
+		for y := 0; y < vm.Height; y++ {
+			for x := 0; x < vm.Width; x++ {
+				if y+n < vm.Height {
+					vm.Display[p][y][x] = vm.Display[p][y+n][x]
+				} else {
+					vm.Display[p][y][x] = false
+				}
+			}
 		}
 	}
 }
 
+// scrollHorizontal shifts every selected plane left or right by n
+// columns.
+func (vm *VirtualMachine) scrollHorizontal(n int, right bool) {
+	for p := 0; p < NumberOfPlanes; p++ {
+		if vm.plane&(1<<p) == 0 {
+			continue
+		}
+		for y := 0; y < vm.Height; y++ {
+			row := vm.Display[p][y]
+			var shifted [HiResDisplayWidth]bool
+			for x := 0; x < vm.Width; x++ {
+				if right {
+					if x-n >= 0 {
+						shifted[x] = row[x-n]
+					}
+				} else if x+n < vm.Width {
+					shifted[x] = row[x+n]
+				}
+			}
+			vm.Display[p][y] = shifted
+		}
+	}
+}
+
+// SaveFlags persists the RPL user flags (Fx75) to the flags file for
+// the currently loaded ROM, defaulting to "chip8.flags" if LoadROM was
+// never called.
+func (vm *VirtualMachine) SaveFlags() error {
+	return os.WriteFile(vm.flagsFile(), vm.rplFlags[:], 0o644)
+}
+
+// LoadFlags restores the RPL user flags (Fx85) from the flags file. A
+// missing file is treated as all-zero flags, matching how a fresh
+// SUPER-CHIP interpreter behaves before any flags have been saved.
+func (vm *VirtualMachine) LoadFlags() error {
+	data, err := os.ReadFile(vm.flagsFile())
+	if os.IsNotExist(err) {
+		vm.rplFlags = [NumberOfRegisters]uint8{}
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	copy(vm.rplFlags[:], data)
+	return nil
+}
+
+func (vm *VirtualMachine) flagsFile() string {
+	if vm.flagsPath == "" {
+		return "chip8.flags"
+	}
+	return vm.flagsPath
+}
+
 //=====================================================================
 // CPU Cycle
 //=====================================================================
@@ -219,7 +462,34 @@ func (vm *VirtualMachine) execute() error {
 }
 
 func (vm *VirtualMachine) executeOp0x0() error {
-	switch vm.decodeNNN() {
+	nnn := vm.decodeNNN()
+
+	if vm.Mode != ModeChip8 {
+		switch {
+		case nnn&0xff0 == 0x0c0:
+			vm.scrollDown(int(nnn & 0xf))
+			return nil
+		case nnn&0xff0 == 0x0d0:
+			vm.scrollUp(int(nnn & 0xf))
+			return nil
+		case nnn == 0x0fb:
+			vm.scrollHorizontal(4, true)
+			return nil
+		case nnn == 0x0fc:
+			vm.scrollHorizontal(4, false)
+			return nil
+		case nnn == 0x0fd:
+			return ErrTerminated
+		case nnn == 0x0fe:
+			vm.SetHiRes(false)
+			return nil
+		case nnn == 0x0ff:
+			vm.SetHiRes(true)
+			return nil
+		}
+	}
+
+	switch nnn {
 	case 0x0e0:
 		vm.ClearDisplay()
 	case 0x0ee:
@@ -299,39 +569,57 @@ func (vm *VirtualMachine) executeOp0x8() error {
 		vm.V[x] = vm.V[y]
 	case 0x1:
 		vm.V[x] |= vm.V[y]
+		if vm.Quirks.LogicResetsVF {
+			vm.V[0xf] = 0x0
+		}
 	case 0x2:
 		vm.V[x] &= vm.V[y]
+		if vm.Quirks.LogicResetsVF {
+			vm.V[0xf] = 0x0
+		}
 	case 0x3:
 		vm.V[x] ^= vm.V[y]
+		if vm.Quirks.LogicResetsVF {
+			vm.V[0xf] = 0x0
+		}
 	case 0x4:
-		result := vm.V[x] + vm.V[y]
+		result := uint16(vm.V[x]) + uint16(vm.V[y])
+		flag := uint8(0x0)
 		if result > 0xff {
-			vm.V[0xf] = 0x1
-			vm.V[x] = result & 0xff
-		} else {
-			vm.V[0xf] = 0x0
-			vm.V[x] = result
+			flag = 0x1
 		}
+		vm.V[x] = uint8(result)
+		vm.V[0xf] = flag
 	case 0x5:
+		flag := uint8(0x0)
 		if vm.V[x] > vm.V[y] {
-			vm.V[0xf] = 0x1
-		} else {
-			vm.V[0xf] = 0x0
+			flag = 0x1
 		}
-		vm.V[x] = (vm.V[x] - vm.V[y]) & 0xff
+		vm.V[x] = vm.V[x] - vm.V[y]
+		vm.V[0xf] = flag
 	case 0x6:
-		vm.V[0xf] = vm.V[x] & 0x01
-		vm.V[x] >>= 1
+		src := x
+		if vm.Quirks.ShiftUsesVy {
+			src = y
+		}
+		flag := vm.V[src] & 0x01
+		vm.V[x] = vm.V[src] >> 1
+		vm.V[0xf] = flag
 	case 0x7:
-		if vm.V[x] < vm.V[y] {
-			vm.V[0xf] = 0x1
-		} else {
-			vm.V[0xf] = 0x0
+		flag := uint8(0x0)
+		if vm.V[y] > vm.V[x] {
+			flag = 0x1
 		}
-		vm.V[x] = (vm.V[y] - vm.V[x]) & 0xff
+		vm.V[x] = vm.V[y] - vm.V[x]
+		vm.V[0xf] = flag
 	case 0xe:
-		vm.V[0xf] = vm.V[x] >> 7
-		vm.V[x] = (vm.V[x] << 1) & 0xff
+		src := x
+		if vm.Quirks.ShiftUsesVy {
+			src = y
+		}
+		flag := vm.V[src] >> 7
+		vm.V[x] = vm.V[src] << 1
+		vm.V[0xf] = flag
 	}
 
 	return nil
@@ -350,7 +638,12 @@ func (vm *VirtualMachine) executeOp0xA() error {
 }
 
 func (vm *VirtualMachine) executeOp0xB() error {
-	addr := (vm.decodeNNN() + uint16(vm.V[0x0])) & 0xfff
+	offset := vm.V[0x0]
+	if vm.Quirks.JumpUsesVx {
+		offset = vm.V[vm.decodeX()]
+	}
+
+	addr := (vm.decodeNNN() + uint16(offset)) & 0xfff
 	if addr < ProgramStartAddress {
 		return InvalidJumpError(vm.PC, addr)
 	}
@@ -360,30 +653,75 @@ func (vm *VirtualMachine) executeOp0xB() error {
 }
 
 func (vm *VirtualMachine) executeOp0xC() error {
-	vm.V[vm.decodeX()] = uint8(rand.Uint32()) & vm.decodeKK()
+	vm.V[vm.decodeX()] = uint8(vm.Rand.Uint32()) & vm.decodeKK()
 	return nil
 }
 
 func (vm *VirtualMachine) executeOp0xD() error {
+	if vm.Quirks.DisplayWait && vm.VBlank != nil {
+		<-vm.VBlank
+	}
+
 	vm.V[0xf] = 0x0
 
-	vx := vm.V[vm.decodeX()]
-	vy := vm.V[vm.decodeY()]
+	vx := uint16(vm.V[vm.decodeX()])
+	vy := uint16(vm.V[vm.decodeY()])
+	n := vm.decodeN()
+
+	width, height := uint16(8), uint16(n)
+	if n == 0 && vm.Mode != ModeChip8 {
+		// Dxy0: a 16x16 big sprite, two bytes per row.
+		width, height = 16, 16
+	}
 
-	for n := uint8(0); n < vm.decodeN(); n++ {
-		y := (vy + n) % DisplayHeight
-		sprite := vm.Memory[(vm.I+uint16(n))%MemorySize]
+	addr := vm.I
+	for p := 0; p < NumberOfPlanes; p++ {
+		if vm.plane&(1<<p) == 0 {
+			continue
+		}
 
-		for i := uint8(7); sprite > 0x00; i-- {
-			x := (vx + i) % DisplayWidth
+		collided := false
+		for row := uint16(0); row < height; row++ {
+			y := vy + row
+			if y >= uint16(vm.Height) {
+				if vm.Quirks.SpriteClipping {
+					continue
+				}
+				y %= uint16(vm.Height)
+			}
 
-			bit := sprite&0x1 == 0x1
-			if bit && vm.Display[y][x] {
-				vm.V[0xf] = 0x1
+			var bits uint32
+			if width == 16 {
+				bits = uint32(vm.Memory[(addr)%MemorySize])<<8 | uint32(vm.Memory[(addr+1)%MemorySize])
+				addr += 2
+			} else {
+				bits = uint32(vm.Memory[addr%MemorySize])
+				addr++
 			}
 
-			sprite >>= 1
-			vm.Display[y][x] = vm.Display[y][x] != bit
+			for col := uint16(0); col < width; col++ {
+				bit := bits&(1<<(width-1-col)) != 0
+				if !bit {
+					continue
+				}
+
+				x := vx + col
+				if x >= uint16(vm.Width) {
+					if vm.Quirks.SpriteClipping {
+						continue
+					}
+					x %= uint16(vm.Width)
+				}
+
+				if vm.Display[p][y][x] {
+					collided = true
+				}
+				vm.Display[p][y][x] = !vm.Display[p][y][x]
+			}
+		}
+
+		if collided {
+			vm.V[0xf] = 0x1
 		}
 	}
 
@@ -437,10 +775,65 @@ func (vm *VirtualMachine) executeOp0xF() error {
 		for i := uint16(0); i <= uint16(x); i++ {
 			vm.Memory[vm.I+i] = vm.V[i]
 		}
+		if vm.Quirks.LoadStoreIncrementsI {
+			if vm.Quirks.MemoryIncrementByX {
+				vm.I += uint16(x)
+			} else {
+				vm.I += uint16(x) + 1
+			}
+		}
 	case 0x65:
 		for i := uint8(0); i <= x; i++ {
 			vm.V[i] = vm.Memory[vm.I+uint16(i)]
 		}
+		if vm.Quirks.LoadStoreIncrementsI {
+			if vm.Quirks.MemoryIncrementByX {
+				vm.I += uint16(x)
+			} else {
+				vm.I += uint16(x) + 1
+			}
+		}
+	case 0x30:
+		if vm.Mode != ModeChip8 {
+			vm.I = uint16(bigFontOffset) + uint16(vm.V[x])*BigFontSize
+		}
+	case 0x75:
+		if vm.Mode != ModeChip8 {
+			for i := uint8(0); i <= x && i < NumberOfRegisters; i++ {
+				vm.rplFlags[i] = vm.V[i]
+			}
+			return vm.SaveFlags()
+		}
+	case 0x85:
+		if vm.Mode != ModeChip8 {
+			if err := vm.LoadFlags(); err != nil {
+				return err
+			}
+			for i := uint8(0); i <= x && i < NumberOfRegisters; i++ {
+				vm.V[i] = vm.rplFlags[i]
+			}
+		}
+	case 0x01:
+		if vm.Mode == ModeXOChip {
+			vm.plane = x & 0x3
+		}
+	case 0x02:
+		if vm.Mode == ModeXOChip {
+			for i := 0; i < len(vm.AudioPattern); i++ {
+				vm.AudioPattern[i] = vm.Memory[(vm.I+uint16(i))%MemorySize]
+			}
+		}
+	case 0x3a:
+		if vm.Mode == ModeXOChip {
+			vm.Pitch = vm.V[x]
+		}
+	case 0x00:
+		if vm.Mode == ModeXOChip && x == 0x0 {
+			// F000 NNNN: load the 16-bit address that follows this
+			// opcode directly into I.
+			vm.I = (uint16(vm.Memory[vm.PC]) << 8) | uint16(vm.Memory[vm.PC+1])
+			vm.PC += 2
+		}
 	}
 
 	return nil
@@ -0,0 +1,165 @@
+// Package asm assembles human-readable CHIP-8 assembly into the byte
+// slices VirtualMachine.LoadProgram expects, and disassembles them
+// back into annotated Instructions. The base opcode table is
+// registered in mnemonics.go; a later SCHIP/XO-CHIP extension package
+// can add its own mnemonics by calling RegisterMnemonic from an init
+// function without touching this package.
+package asm
+
+import "fmt"
+
+// ProgramStartAddress is the memory address CHIP-8 programs are
+// loaded at, mirroring chip8.ProgramStartAddress. Kept as a local
+// constant instead of importing the chip8 package so this package
+// stays a portable text-to-bytes assembler, independent of chip8's
+// cgo/SDL2 dependency.
+const ProgramStartAddress = 0x200
+
+// OperandKind describes the grammar of one operand of a mnemonic, for
+// both the assembler (parsing source tokens) and the disassembler
+// (rendering operand strings from opcode bits).
+type OperandKind int
+
+const (
+	// OperandReg is a register operand, written Vx/V0..VF.
+	OperandReg OperandKind = iota
+
+	// OperandRegV0 is the literal register V0, used by JP V0, addr.
+	OperandRegV0
+
+	// OperandByte is an 8-bit immediate, a number or an EQU constant.
+	OperandByte
+
+	// OperandNibble is a 4-bit immediate, a number or an EQU constant.
+	OperandNibble
+
+	// OperandAddr is a 12-bit address, a number or a label.
+	OperandAddr
+
+	// OperandDT is the literal delay timer register, DT.
+	OperandDT
+
+	// OperandST is the literal sound timer register, ST.
+	OperandST
+
+	// OperandK is the literal keypress operand, K.
+	OperandK
+
+	// OperandF is the literal font-sprite operand, F.
+	OperandF
+
+	// OperandB is the literal BCD operand, B.
+	OperandB
+
+	// OperandI is the literal index register, I.
+	OperandI
+
+	// OperandIndirectI is the literal indirect operand, [I].
+	OperandIndirectI
+)
+
+// MnemonicSpec registers one assembly mnemonic with both its
+// assembler grammar/encoder and its disassembler opcode pattern. Name
+// may be shared across several specs (e.g. "LD" has a different
+// Operands shape per overload); the assembler tries each same-named
+// spec in registration order until one matches the parsed operand
+// count and kinds, and the disassembler tries specs from the most
+// specific Mask (most bits set) to the least, in registration order
+// among equally specific ones, until one matches Mask/Value.
+type MnemonicSpec struct {
+	// Name is the mnemonic keyword, matched case-insensitively.
+	Name string
+
+	// Operands is the operand grammar used by the assembler to parse
+	// source tokens, and by the disassembler to render operand
+	// strings.
+	Operands []OperandKind
+
+	// Encode packs the parsed operand values (registers/nibbles as
+	// their numeric value, addresses already resolved from any label)
+	// into the final opcode word.
+	Encode func(vals []uint16) uint16
+
+	// Mask and Value identify this spec's opcodes for disassembly:
+	// opcode&Mask == Value.
+	Mask  uint16
+	Value uint16
+}
+
+// Instruction is one disassembled opcode.
+type Instruction struct {
+	Addr     uint16
+	Opcode   uint16
+	Mnemonic string
+	Operands string
+	Label    string // set if some other instruction targets Addr
+}
+
+var registry []MnemonicSpec
+
+// RegisterMnemonic adds a mnemonic to both the assembler and
+// disassembler. Intended to be called from an init function, the way
+// mnemonics.go registers the base opcode table.
+func RegisterMnemonic(spec MnemonicSpec) {
+	registry = append(registry, spec)
+}
+
+func specsNamed(name string) []MnemonicSpec {
+	var specs []MnemonicSpec
+	for _, s := range registry {
+		if eqFold(s.Name, name) {
+			specs = append(specs, s)
+		}
+	}
+	return specs
+}
+
+func eqFold(a, b string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := 0; i < len(a); i++ {
+		ca, cb := a[i], b[i]
+		if 'a' <= ca && ca <= 'z' {
+			ca -= 'a' - 'A'
+		}
+		if 'a' <= cb && cb <= 'z' {
+			cb -= 'a' - 'A'
+		}
+		if ca != cb {
+			return false
+		}
+	}
+	return true
+}
+
+func operandWidth(kind OperandKind) string {
+	switch kind {
+	case OperandReg:
+		return "Vx"
+	case OperandRegV0:
+		return "V0"
+	case OperandByte:
+		return "byte"
+	case OperandNibble:
+		return "nibble"
+	case OperandAddr:
+		return "addr"
+	case OperandDT:
+		return "DT"
+	case OperandST:
+		return "ST"
+	case OperandK:
+		return "K"
+	case OperandF:
+		return "F"
+	case OperandB:
+		return "B"
+	case OperandI:
+		return "I"
+	case OperandIndirectI:
+		return "[I]"
+	default:
+		return fmt.Sprintf("?%d", kind)
+	}
+}
@@ -0,0 +1,339 @@
+package asm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type sourceLine struct {
+	number int
+	label  string
+	text   string // with any label prefix stripped
+}
+
+// Assemble turns CHIP-8 assembly source into a byte slice suitable
+// for VirtualMachine.LoadProgram. Labels, EQU constants, and the .db
+// data directive are handled here; opcode mnemonics come from the
+// registry populated by RegisterMnemonic (mnemonics.go registers the
+// base opcode table).
+func Assemble(source string) ([]byte, error) {
+	lines, err := splitLines(source)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, constants, err := resolveSymbols(lines)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []byte
+	addr := uint16(ProgramStartAddress)
+
+	for _, line := range lines {
+		if line.text == "" || isEquDirective(line.text) {
+			continue
+		}
+
+		if isDataDirective(line.text) {
+			bytes, err := assembleData(line.text, constants)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line.number, err)
+			}
+			out = append(out, bytes...)
+			addr += uint16(len(bytes))
+			continue
+		}
+
+		opcode, err := assembleInstruction(line.text, addr, labels, constants)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line.number, err)
+		}
+		out = append(out, byte(opcode>>8), byte(opcode))
+		addr += 2
+	}
+
+	return out, nil
+}
+
+func splitLines(source string) ([]sourceLine, error) {
+	var lines []sourceLine
+
+	for i, raw := range strings.Split(source, "\n") {
+		text := stripComment(raw)
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+
+		label := ""
+		if colon := strings.Index(text, ":"); colon >= 0 && !strings.ContainsAny(text[:colon], " \t,") {
+			label = text[:colon]
+			text = strings.TrimSpace(text[colon+1:])
+		}
+
+		lines = append(lines, sourceLine{number: i + 1, label: label, text: text})
+	}
+
+	return lines, nil
+}
+
+func stripComment(line string) string {
+	if i := strings.IndexByte(line, ';'); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
+
+// resolveSymbols makes a first pass over the source computing every
+// label's address and every EQU constant's value, without emitting
+// any bytes.
+func resolveSymbols(lines []sourceLine) (labels, constants map[string]uint16, err error) {
+	labels = map[string]uint16{}
+	constants = map[string]uint16{}
+	addr := uint16(ProgramStartAddress)
+
+	for _, line := range lines {
+		if line.label != "" {
+			labels[strings.ToUpper(line.label)] = addr
+		}
+
+		switch {
+		case line.text == "":
+			continue
+
+		case isEquDirective(line.text):
+			name, value, err := parseEquDirective(line.text, constants)
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: %w", line.number, err)
+			}
+			constants[strings.ToUpper(name)] = value
+
+		case isDataDirective(line.text):
+			n, err := countDataBytes(line.text)
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: %w", line.number, err)
+			}
+			addr += uint16(n)
+
+		default:
+			addr += 2
+		}
+	}
+
+	return labels, constants, nil
+}
+
+func isEquDirective(text string) bool {
+	fields := strings.Fields(text)
+	return len(fields) >= 2 && eqFold(fields[1], "EQU")
+}
+
+func parseEquDirective(text string, constants map[string]uint16) (name string, value uint16, err error) {
+	fields := strings.Fields(text)
+	if len(fields) != 3 {
+		return "", 0, fmt.Errorf("malformed EQU directive: %q", text)
+	}
+
+	value, err = parseNumber(fields[2], constants)
+	if err != nil {
+		return "", 0, err
+	}
+	return fields[0], value, nil
+}
+
+func isDataDirective(text string) bool {
+	fields := strings.Fields(text)
+	return len(fields) >= 1 && eqFold(fields[0], ".db")
+}
+
+func countDataBytes(text string) (int, error) {
+	_, rest, ok := strings.Cut(text, " ")
+	if !ok {
+		return 0, nil
+	}
+	return len(strings.Split(rest, ",")), nil
+}
+
+func assembleData(text string, constants map[string]uint16) ([]byte, error) {
+	_, rest, ok := strings.Cut(text, " ")
+	if !ok {
+		return nil, nil
+	}
+
+	var out []byte
+	for _, tok := range strings.Split(rest, ",") {
+		value, err := parseNumber(strings.TrimSpace(tok), constants)
+		if err != nil {
+			return nil, err
+		}
+		if value > 0xFF {
+			return nil, fmt.Errorf(".db value out of range: %q", tok)
+		}
+		out = append(out, byte(value))
+	}
+	return out, nil
+}
+
+func assembleInstruction(text string, addr uint16, labels, constants map[string]uint16) (uint16, error) {
+	name, rawOperands := splitMnemonic(text)
+
+	specs := specsNamed(name)
+	if len(specs) == 0 {
+		return 0, fmt.Errorf("unknown mnemonic: %s", name)
+	}
+
+	var lastErr error
+	for _, spec := range specs {
+		vals, err := matchOperands(spec.Operands, rawOperands, labels, constants)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return spec.Encode(vals), nil
+	}
+	return 0, fmt.Errorf("%s: %w", name, lastErr)
+}
+
+func splitMnemonic(text string) (name string, operands []string) {
+	fields := strings.SplitN(text, " ", 2)
+	name = fields[0]
+	if len(fields) == 1 {
+		return name, nil
+	}
+
+	for _, tok := range strings.Split(fields[1], ",") {
+		operands = append(operands, strings.TrimSpace(tok))
+	}
+	return name, operands
+}
+
+// matchOperands tries to parse tok against kinds, one operand per
+// kind. It fails (without side effects) if the token count or shape
+// doesn't match, so the caller can try the next overload of an
+// overloaded mnemonic name.
+func matchOperands(kinds []OperandKind, tokens []string, labels, constants map[string]uint16) ([]uint16, error) {
+	if len(tokens) != len(kinds) {
+		return nil, fmt.Errorf("expected %d operand(s), got %d", len(kinds), len(tokens))
+	}
+
+	var vals []uint16
+	for i, kind := range kinds {
+		tok := tokens[i]
+
+		switch kind {
+		case OperandReg:
+			reg, err := parseRegister(tok)
+			if err != nil {
+				return nil, err
+			}
+			vals = append(vals, reg)
+
+		case OperandRegV0:
+			if !eqFold(tok, "V0") {
+				return nil, fmt.Errorf("expected V0, got %q", tok)
+			}
+
+		case OperandByte:
+			v, err := parseNumber(tok, constants)
+			if err != nil {
+				return nil, err
+			}
+			if v > 0xFF {
+				return nil, fmt.Errorf("byte operand out of range: %q", tok)
+			}
+			vals = append(vals, v)
+
+		case OperandNibble:
+			v, err := parseNumber(tok, constants)
+			if err != nil {
+				return nil, err
+			}
+			if v > 0xF {
+				return nil, fmt.Errorf("nibble operand out of range: %q", tok)
+			}
+			vals = append(vals, v)
+
+		case OperandAddr:
+			if v, ok := labels[strings.ToUpper(tok)]; ok {
+				vals = append(vals, v)
+				continue
+			}
+			v, err := parseNumber(tok, constants)
+			if err != nil {
+				return nil, err
+			}
+			if v > 0xFFF {
+				return nil, fmt.Errorf("address operand out of range: %q", tok)
+			}
+			vals = append(vals, v)
+
+		case OperandDT:
+			if !eqFold(tok, "DT") {
+				return nil, fmt.Errorf("expected DT, got %q", tok)
+			}
+		case OperandST:
+			if !eqFold(tok, "ST") {
+				return nil, fmt.Errorf("expected ST, got %q", tok)
+			}
+		case OperandK:
+			if !eqFold(tok, "K") {
+				return nil, fmt.Errorf("expected K, got %q", tok)
+			}
+		case OperandF:
+			if !eqFold(tok, "F") {
+				return nil, fmt.Errorf("expected F, got %q", tok)
+			}
+		case OperandB:
+			if !eqFold(tok, "B") {
+				return nil, fmt.Errorf("expected B, got %q", tok)
+			}
+		case OperandI:
+			if !eqFold(tok, "I") {
+				return nil, fmt.Errorf("expected I, got %q", tok)
+			}
+		case OperandIndirectI:
+			if !eqFold(tok, "[I]") {
+				return nil, fmt.Errorf("expected [I], got %q", tok)
+			}
+		}
+	}
+
+	return vals, nil
+}
+
+func parseRegister(tok string) (uint16, error) {
+	if len(tok) != 2 || (tok[0] != 'V' && tok[0] != 'v') {
+		return 0, fmt.Errorf("expected a register (Vx), got %q", tok)
+	}
+
+	v, err := strconv.ParseUint(tok[1:], 16, 8)
+	if err != nil || v > 0xF {
+		return 0, fmt.Errorf("expected a register (Vx), got %q", tok)
+	}
+	return uint16(v), nil
+}
+
+func parseNumber(tok string, constants map[string]uint16) (uint16, error) {
+	if v, ok := constants[strings.ToUpper(tok)]; ok {
+		return v, nil
+	}
+
+	base := 10
+	digits := tok
+	switch {
+	case strings.HasPrefix(tok, "0x"), strings.HasPrefix(tok, "0X"):
+		base = 16
+		digits = tok[2:]
+	case strings.HasPrefix(tok, "$"):
+		base = 16
+		digits = tok[1:]
+	}
+
+	v, err := strconv.ParseUint(digits, base, 16)
+	if err != nil {
+		return 0, fmt.Errorf("expected a number or constant, got %q", tok)
+	}
+	return uint16(v), nil
+}
@@ -0,0 +1,145 @@
+package asm
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+	"strings"
+)
+
+// Disassemble decodes program bytes (as loaded by LoadProgram, i.e.
+// starting at ProgramStartAddress) into annotated Instructions,
+// labeling any address that a JP or CALL targets.
+func Disassemble(program []byte) []Instruction {
+	base := uint16(ProgramStartAddress)
+	order := decodeOrder()
+	instructions := make([]Instruction, 0, len(program)/2)
+
+	for i := 0; i+1 < len(program); i += 2 {
+		addr := base + uint16(i)
+		opcode := uint16(program[i])<<8 | uint16(program[i+1])
+		instructions = append(instructions, decode(addr, opcode, order))
+	}
+
+	labelControlFlowTargets(instructions)
+	return instructions
+}
+
+// decodeOrder lists registry indices from most specific Mask (more
+// bits fixed, e.g. CLS's exact 0xFFFF) to least specific (e.g. SYS's
+// 0xF000), so a broad fallback mnemonic registered early never
+// shadows a narrower one a later extension package registers.
+func decodeOrder() []int {
+	order := make([]int, len(registry))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return bits.OnesCount16(registry[order[a]].Mask) > bits.OnesCount16(registry[order[b]].Mask)
+	})
+	return order
+}
+
+func decode(addr, opcode uint16, order []int) Instruction {
+	for _, i := range order {
+		spec := registry[i]
+		if opcode&spec.Mask != spec.Value {
+			continue
+		}
+		return Instruction{
+			Addr:     addr,
+			Opcode:   opcode,
+			Mnemonic: spec.Name,
+			Operands: renderOperands(spec, opcode),
+		}
+	}
+
+	return Instruction{
+		Addr:     addr,
+		Opcode:   opcode,
+		Mnemonic: "DW",
+		Operands: fmt.Sprintf("0x%04X", opcode),
+	}
+}
+
+// renderOperands extracts the operand nibbles/byte/addr out of opcode
+// using the fixed convention every registered spec's Encode follows:
+// the first OperandReg is always x (bits 8-11), the second is always
+// y (bits 4-7), OperandNibble is always bits 0-3, OperandByte is
+// always bits 0-7, and OperandAddr is always bits 0-11.
+func renderOperands(spec MnemonicSpec, opcode uint16) string {
+	x := (opcode >> 8) & 0xF
+	y := (opcode >> 4) & 0xF
+
+	seenReg := 0
+	parts := make([]string, 0, len(spec.Operands))
+
+	for _, kind := range spec.Operands {
+		switch kind {
+		case OperandReg:
+			if seenReg == 0 {
+				parts = append(parts, fmt.Sprintf("V%X", x))
+			} else {
+				parts = append(parts, fmt.Sprintf("V%X", y))
+			}
+			seenReg++
+		case OperandNibble:
+			parts = append(parts, fmt.Sprintf("%d", opcode&0xF))
+		case OperandByte:
+			parts = append(parts, fmt.Sprintf("0x%02X", opcode&0xFF))
+		case OperandAddr:
+			parts = append(parts, fmt.Sprintf("0x%03X", opcode&0xFFF))
+		default:
+			parts = append(parts, operandWidth(kind))
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// labelControlFlowTargets finds every address a JP or CALL
+// instruction targets, and, if that address was itself disassembled,
+// sets its Label and rewrites the jump/call's Operands to reference
+// the label instead of a raw address.
+func labelControlFlowTargets(instructions []Instruction) {
+	byAddr := make(map[uint16]*Instruction, len(instructions))
+	for i := range instructions {
+		byAddr[instructions[i].Addr] = &instructions[i]
+	}
+
+	for i := range instructions {
+		instr := &instructions[i]
+		if instr.Mnemonic != "JP" && instr.Mnemonic != "CALL" {
+			continue
+		}
+
+		target, ok := jumpTarget(instr)
+		if !ok {
+			continue
+		}
+
+		targetInstr, ok := byAddr[target]
+		if !ok {
+			continue
+		}
+
+		if targetInstr.Label == "" {
+			targetInstr.Label = fmt.Sprintf("L_%03X", target)
+		}
+		instr.Operands = rewriteAddrOperand(instr.Operands, target, targetInstr.Label)
+	}
+}
+
+func jumpTarget(instr *Instruction) (uint16, bool) {
+	switch instr.Mnemonic {
+	case "JP", "CALL":
+		return instr.Opcode & 0xFFF, true
+	default:
+		return 0, false
+	}
+}
+
+func rewriteAddrOperand(operands string, target uint16, label string) string {
+	raw := fmt.Sprintf("0x%03X", target)
+	return strings.Replace(operands, raw, label, 1)
+}
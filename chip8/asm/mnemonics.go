@@ -0,0 +1,187 @@
+package asm
+
+// init registers the base CHIP-8 opcode table (the instructions
+// already implemented in chip8.VirtualMachine's executeOp0..executeOpF
+// before any SCHIP/XO-CHIP extension). A SCHIP/XO-CHIP package can
+// register its own additional mnemonics (SCD, SCR, DRW Vx,Vy,0, etc.)
+// the same way, via RegisterMnemonic.
+func init() {
+	reg := func(name string, operands []OperandKind, mask, value uint16, encode func(vals []uint16) uint16) {
+		RegisterMnemonic(MnemonicSpec{Name: name, Operands: operands, Mask: mask, Value: value, Encode: encode})
+	}
+
+	// 00E0 - CLS
+	reg("CLS", nil, 0xFFFF, 0x00E0, func(vals []uint16) uint16 {
+		return 0x00E0
+	})
+
+	// 00EE - RET
+	reg("RET", nil, 0xFFFF, 0x00EE, func(vals []uint16) uint16 {
+		return 0x00EE
+	})
+
+	// 0nnn - SYS addr (accepted by the assembler, ignored by the VM)
+	reg("SYS", []OperandKind{OperandAddr}, 0xF000, 0x0000, func(vals []uint16) uint16 {
+		return vals[0]
+	})
+
+	// 1nnn - JP addr
+	reg("JP", []OperandKind{OperandAddr}, 0xF000, 0x1000, func(vals []uint16) uint16 {
+		return 0x1000 | vals[0]
+	})
+
+	// 2nnn - CALL addr
+	reg("CALL", []OperandKind{OperandAddr}, 0xF000, 0x2000, func(vals []uint16) uint16 {
+		return 0x2000 | vals[0]
+	})
+
+	// 3xkk - SE Vx, byte
+	reg("SE", []OperandKind{OperandReg, OperandByte}, 0xF000, 0x3000, func(vals []uint16) uint16 {
+		return 0x3000 | vals[0]<<8 | vals[1]
+	})
+
+	// 4xkk - SNE Vx, byte
+	reg("SNE", []OperandKind{OperandReg, OperandByte}, 0xF000, 0x4000, func(vals []uint16) uint16 {
+		return 0x4000 | vals[0]<<8 | vals[1]
+	})
+
+	// 5xy0 - SE Vx, Vy
+	reg("SE", []OperandKind{OperandReg, OperandReg}, 0xF00F, 0x5000, func(vals []uint16) uint16 {
+		return 0x5000 | vals[0]<<8 | vals[1]<<4
+	})
+
+	// 6xkk - LD Vx, byte
+	reg("LD", []OperandKind{OperandReg, OperandByte}, 0xF000, 0x6000, func(vals []uint16) uint16 {
+		return 0x6000 | vals[0]<<8 | vals[1]
+	})
+
+	// 7xkk - ADD Vx, byte
+	reg("ADD", []OperandKind{OperandReg, OperandByte}, 0xF000, 0x7000, func(vals []uint16) uint16 {
+		return 0x7000 | vals[0]<<8 | vals[1]
+	})
+
+	// 8xy0 - LD Vx, Vy
+	reg("LD", []OperandKind{OperandReg, OperandReg}, 0xF00F, 0x8000, func(vals []uint16) uint16 {
+		return 0x8000 | vals[0]<<8 | vals[1]<<4
+	})
+
+	// 8xy1 - OR Vx, Vy
+	reg("OR", []OperandKind{OperandReg, OperandReg}, 0xF00F, 0x8001, func(vals []uint16) uint16 {
+		return 0x8001 | vals[0]<<8 | vals[1]<<4
+	})
+
+	// 8xy2 - AND Vx, Vy
+	reg("AND", []OperandKind{OperandReg, OperandReg}, 0xF00F, 0x8002, func(vals []uint16) uint16 {
+		return 0x8002 | vals[0]<<8 | vals[1]<<4
+	})
+
+	// 8xy3 - XOR Vx, Vy
+	reg("XOR", []OperandKind{OperandReg, OperandReg}, 0xF00F, 0x8003, func(vals []uint16) uint16 {
+		return 0x8003 | vals[0]<<8 | vals[1]<<4
+	})
+
+	// 8xy4 - ADD Vx, Vy
+	reg("ADD", []OperandKind{OperandReg, OperandReg}, 0xF00F, 0x8004, func(vals []uint16) uint16 {
+		return 0x8004 | vals[0]<<8 | vals[1]<<4
+	})
+
+	// 8xy5 - SUB Vx, Vy
+	reg("SUB", []OperandKind{OperandReg, OperandReg}, 0xF00F, 0x8005, func(vals []uint16) uint16 {
+		return 0x8005 | vals[0]<<8 | vals[1]<<4
+	})
+
+	// 8xy6 - SHR Vx, Vy
+	reg("SHR", []OperandKind{OperandReg, OperandReg}, 0xF00F, 0x8006, func(vals []uint16) uint16 {
+		return 0x8006 | vals[0]<<8 | vals[1]<<4
+	})
+
+	// 8xy7 - SUBN Vx, Vy
+	reg("SUBN", []OperandKind{OperandReg, OperandReg}, 0xF00F, 0x8007, func(vals []uint16) uint16 {
+		return 0x8007 | vals[0]<<8 | vals[1]<<4
+	})
+
+	// 8xyE - SHL Vx, Vy
+	reg("SHL", []OperandKind{OperandReg, OperandReg}, 0xF00F, 0x800E, func(vals []uint16) uint16 {
+		return 0x800E | vals[0]<<8 | vals[1]<<4
+	})
+
+	// 9xy0 - SNE Vx, Vy
+	reg("SNE", []OperandKind{OperandReg, OperandReg}, 0xF00F, 0x9000, func(vals []uint16) uint16 {
+		return 0x9000 | vals[0]<<8 | vals[1]<<4
+	})
+
+	// Annn - LD I, addr
+	reg("LD", []OperandKind{OperandI, OperandAddr}, 0xF000, 0xA000, func(vals []uint16) uint16 {
+		return 0xA000 | vals[0]
+	})
+
+	// Bnnn - JP V0, addr
+	reg("JP", []OperandKind{OperandRegV0, OperandAddr}, 0xF000, 0xB000, func(vals []uint16) uint16 {
+		return 0xB000 | vals[0]
+	})
+
+	// Cxkk - RND Vx, byte
+	reg("RND", []OperandKind{OperandReg, OperandByte}, 0xF000, 0xC000, func(vals []uint16) uint16 {
+		return 0xC000 | vals[0]<<8 | vals[1]
+	})
+
+	// Dxyn - DRW Vx, Vy, nibble
+	reg("DRW", []OperandKind{OperandReg, OperandReg, OperandNibble}, 0xF000, 0xD000, func(vals []uint16) uint16 {
+		return 0xD000 | vals[0]<<8 | vals[1]<<4 | vals[2]
+	})
+
+	// Ex9E - SKP Vx
+	reg("SKP", []OperandKind{OperandReg}, 0xF0FF, 0xE09E, func(vals []uint16) uint16 {
+		return 0xE09E | vals[0]<<8
+	})
+
+	// ExA1 - SKNP Vx
+	reg("SKNP", []OperandKind{OperandReg}, 0xF0FF, 0xE0A1, func(vals []uint16) uint16 {
+		return 0xE0A1 | vals[0]<<8
+	})
+
+	// Fx07 - LD Vx, DT
+	reg("LD", []OperandKind{OperandReg, OperandDT}, 0xF0FF, 0xF007, func(vals []uint16) uint16 {
+		return 0xF007 | vals[0]<<8
+	})
+
+	// Fx0A - LD Vx, K
+	reg("LD", []OperandKind{OperandReg, OperandK}, 0xF0FF, 0xF00A, func(vals []uint16) uint16 {
+		return 0xF00A | vals[0]<<8
+	})
+
+	// Fx15 - LD DT, Vx
+	reg("LD", []OperandKind{OperandDT, OperandReg}, 0xF0FF, 0xF015, func(vals []uint16) uint16 {
+		return 0xF015 | vals[0]<<8
+	})
+
+	// Fx18 - LD ST, Vx
+	reg("LD", []OperandKind{OperandST, OperandReg}, 0xF0FF, 0xF018, func(vals []uint16) uint16 {
+		return 0xF018 | vals[0]<<8
+	})
+
+	// Fx1E - ADD I, Vx
+	reg("ADD", []OperandKind{OperandI, OperandReg}, 0xF0FF, 0xF01E, func(vals []uint16) uint16 {
+		return 0xF01E | vals[0]<<8
+	})
+
+	// Fx29 - LD F, Vx
+	reg("LD", []OperandKind{OperandF, OperandReg}, 0xF0FF, 0xF029, func(vals []uint16) uint16 {
+		return 0xF029 | vals[0]<<8
+	})
+
+	// Fx33 - LD B, Vx
+	reg("LD", []OperandKind{OperandB, OperandReg}, 0xF0FF, 0xF033, func(vals []uint16) uint16 {
+		return 0xF033 | vals[0]<<8
+	})
+
+	// Fx55 - LD [I], Vx
+	reg("LD", []OperandKind{OperandIndirectI, OperandReg}, 0xF0FF, 0xF055, func(vals []uint16) uint16 {
+		return 0xF055 | vals[0]<<8
+	})
+
+	// Fx65 - LD Vx, [I]
+	reg("LD", []OperandKind{OperandReg, OperandIndirectI}, 0xF0FF, 0xF065, func(vals []uint16) uint16 {
+		return 0xF065 | vals[0]<<8
+	})
+}
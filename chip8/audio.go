@@ -0,0 +1,138 @@
+package chip8
+
+import (
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+const (
+	// AudioSampleRate is the sample rate (in Hz) the SDL audio device
+	// is opened at.
+	AudioSampleRate = 44100
+
+	// AudioToneFrequency is the frequency (in Hz) of the plain square
+	// wave played for CHIP-8/SUPER-CHIP ROMs that never touch Fx02.
+	AudioToneFrequency = 440
+
+	// DefaultPitch is the playback pitch (in Hz) before any XO-CHIP
+	// Fx3A instruction sets one explicitly.
+	DefaultPitch = 4000
+
+	audioQueueInterval = time.Second / 50
+)
+
+// SDLAudio is an Audio implementation backed by sdl.OpenAudioDevice.
+// While playing, it queues a square wave: either the plain 440 Hz
+// tone, or, once SetPattern has been called with a non-zero pattern,
+// the XO-CHIP audio pattern buffer read as a 128-bit bitstream at the
+// configured pitch.
+type SDLAudio struct {
+	device  sdl.AudioDeviceID
+	playing bool
+	stop    chan struct{}
+
+	pattern [AudioPatternSize]uint8
+	pitch   float64
+	pos     float64
+}
+
+// NewSDLAudio opens the default SDL audio output device.
+func NewSDLAudio() (*SDLAudio, error) {
+	spec := &sdl.AudioSpec{
+		Freq:     AudioSampleRate,
+		Format:   sdl.AUDIO_S16SYS,
+		Channels: 1,
+		Samples:  1024,
+	}
+
+	device, err := sdl.OpenAudioDevice("", false, spec, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SDLAudio{device: device, pitch: DefaultPitch}, nil
+}
+
+// Start begins playback.
+func (a *SDLAudio) Start() error {
+	if a.playing {
+		return nil
+	}
+
+	a.playing = true
+	a.stop = make(chan struct{})
+	sdl.PauseAudioDevice(a.device, false)
+
+	go a.feed(a.stop)
+	return nil
+}
+
+// Stop ends playback.
+func (a *SDLAudio) Stop() error {
+	if !a.playing {
+		return nil
+	}
+
+	a.playing = false
+	close(a.stop)
+	sdl.PauseAudioDevice(a.device, true)
+	sdl.ClearQueuedAudio(a.device)
+	return nil
+}
+
+// SetPattern updates the XO-CHIP audio pattern buffer and playback
+// pitch used once pattern is no longer all zero. A zero pattern (the
+// value before any ROM has executed Fx02) falls back to the plain
+// AudioToneFrequency square wave.
+func (a *SDLAudio) SetPattern(pattern [AudioPatternSize]uint8, pitch float64) {
+	a.pattern = pattern
+	a.pitch = pitch
+}
+
+func (a *SDLAudio) feed(stop chan struct{}) {
+	ticker := time.NewTicker(audioQueueInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			sdl.QueueAudio(a.device, a.nextSamples(AudioSampleRate/50))
+		}
+	}
+}
+
+// nextSamples generates n little-endian 16-bit mono PCM samples.
+func (a *SDLAudio) nextSamples(n int) []byte {
+	samples := make([]byte, n*2)
+
+	hasPattern := a.pattern != [AudioPatternSize]uint8{}
+	bitRate := a.pitch // pattern bits played per second
+
+	for i := 0; i < n; i++ {
+		var on bool
+
+		if hasPattern {
+			bit := int(a.pos) % (AudioPatternSize * 8)
+			on = a.pattern[bit/8]&(1<<(7-uint(bit%8))) != 0
+			a.pos += bitRate / AudioSampleRate
+		} else {
+			period := AudioSampleRate / AudioToneFrequency
+			on = i%period < period/2
+		}
+
+		var sample int16 = 0x4fff
+		if !on {
+			sample = -0x4fff
+		}
+
+		samples[i*2] = byte(sample)
+		samples[i*2+1] = byte(sample >> 8)
+	}
+
+	return samples
+}
+
+var _ Audio = (*SDLAudio)(nil)
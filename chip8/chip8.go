@@ -1,16 +1,58 @@
 package chip8
 
 import (
+	"fmt"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/veandco/go-sdl2/sdl"
 )
 
+const (
+	// DefaultRewindInterval is the default number of cycles between
+	// Rewinder snapshots.
+	DefaultRewindInterval = 60
+
+	// DefaultRewindCapacity is the default number of Rewinder
+	// snapshots kept before the oldest is dropped.
+	DefaultRewindCapacity = 300
+
+	// saveStateSuffix is appended to the ROM filename to derive the
+	// path EventSaveState/EventLoadState read and write.
+	saveStateSuffix = ".state"
+)
+
 type Options struct {
 	Scale int
 	TPS   int
+
+	// Mode selects the CHIP-8 instruction set variant the emulator
+	// runs the loaded ROM as. Defaults to ModeChip8.
+	Mode Mode
+
+	// Quirks selects the platform-specific opcode semantics the
+	// emulator runs the loaded ROM with. Defaults to DefaultQuirks.
+	Quirks Quirks
+
+	// AutoQuirks, when true, overrides Quirks with the profile
+	// registered for the loaded ROM's SHA-1 digest (see
+	// RegisterROMQuirks), if one is known. Falls back to Quirks
+	// otherwise.
+	AutoQuirks bool
+
+	// RewindInterval is the number of cycles between Rewinder
+	// snapshots. Defaults to DefaultRewindInterval.
+	RewindInterval int
+
+	// RewindCapacity is the number of Rewinder snapshots kept before
+	// the oldest is dropped. Defaults to DefaultRewindCapacity.
+	RewindCapacity int
+
+	// Ticker paces the CPU loop. Defaults to a FrameTicker running TPS
+	// cycles per 60Hz frame, the emulator's original fixed schedule.
+	Ticker Ticker
 }
 
 type Event int
@@ -21,6 +63,9 @@ const (
 	EventPause
 	EventReset
 	EventNextCycle
+	EventRewind
+	EventSaveState
+	EventLoadState
 )
 
 var (
@@ -44,8 +89,8 @@ func Start(filename string, opts Options) error {
 		"CHIP-8",
 		sdl.WINDOWPOS_CENTERED,
 		sdl.WINDOWPOS_CENTERED,
-		int32(ScreenWidth*opts.Scale),
-		int32(ScreenHeight*opts.Scale),
+		int32(HiResScreenWidth*opts.Scale),
+		int32(HiResScreenHeight*opts.Scale),
 		sdl.WINDOW_OPENGL|sdl.WINDOW_SHOWN,
 	)
 
@@ -60,21 +105,46 @@ func Start(filename string, opts Options) error {
 	}
 	defer renderer.Destroy()
 
+	audio, err := NewSDLAudio()
+	if err != nil {
+		return err
+	}
+
+	rewindInterval := opts.RewindInterval
+	if rewindInterval <= 0 {
+		rewindInterval = DefaultRewindInterval
+	}
+	rewindCapacity := opts.RewindCapacity
+	if rewindCapacity <= 0 {
+		rewindCapacity = DefaultRewindCapacity
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	quirks := opts.Quirks
+	if opts.AutoQuirks {
+		if detected, ok := LookupROMQuirks(ROMDigest(data)); ok {
+			log.Info(fmt.Sprintf("Auto-detected quirks profile for %s", filename))
+			quirks = detected
+		}
+	}
+
 	var (
 		paused  = false
 		running = true
 
-		vm       = NewVirtualMachine()
+		vm       = NewVirtualMachineWithQuirks(opts.Mode, quirks)
 		keyboard = NewKeyboard()
 		screen   = NewScreen(renderer, int32(opts.Scale))
-		sound    = NewSound()
+		sound    = NewSound(audio)
 		timer    = NewTimer()
+		rewinder = NewRewinder(rewindInterval, rewindCapacity)
 	)
 
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return err
-	}
+	screen.SetWrapSprites(quirks.WrapSprites)
 
 	if err := vm.LoadProgram(data...); err != nil {
 		return err
@@ -82,9 +152,50 @@ func Start(filename string, opts Options) error {
 
 	log.Info("Starting CHIP-8")
 
-	// Runs at roughly 60 FPS
+	ticker := opts.Ticker
+	if ticker == nil {
+		ticker = NewFrameTicker(opts.TPS)
+	}
+
+	// mu guards every field the CPU goroutine and this, the SDL
+	// goroutine, both touch: vm and its peripherals, plus paused and
+	// running.
+	var mu sync.Mutex
+
+	cpuDone := make(chan struct{})
+	go func() {
+		defer close(cpuDone)
+
+		for ticker.Wait() {
+			mu.Lock()
+			if !running || paused {
+				stop := !running
+				mu.Unlock()
+				if stop {
+					return
+				}
+				continue
+			}
+
+			err := vm.Step(keyboard, screen, sound, timer)
+			rewinder.Tick(vm, keyboard, screen, sound, timer)
+			if err != nil && err == ErrHalted {
+				running = false
+			}
+			mu.Unlock()
+
+			if err != nil && err != ErrHalted {
+				log.Error(err)
+			}
+		}
+	}()
+
+	// Runs at roughly 60 FPS, handling input, rendering, and timers
+	// independent of however fast the CPU goroutine is stepping.
 	for range time.Tick(time.Millisecond * 1000 / 60) {
+		mu.Lock()
 		if !running {
+			mu.Unlock()
 			break
 		}
 
@@ -97,34 +208,74 @@ func Start(filename string, opts Options) error {
 
 		case EventReset:
 			vm.Reset()
-			screen.Render()
 
 		case EventNextCycle:
 			if paused {
 				vm.Step(keyboard, screen, sound, timer)
-				screen.Render()
 			}
-		}
 
-		if paused {
-			continue
-		}
+		case EventRewind:
+			if state, ok := rewinder.Pop(); ok {
+				if err := vm.Restore(state, keyboard, screen, sound, timer); err != nil {
+					log.Error(err)
+				}
+			}
 
-		sound.Step()
-		timer.Step()
+		case EventSaveState:
+			state := vm.Snapshot(keyboard, screen, sound, timer)
+			mu.Unlock()
+			if err := saveStateToFile(filename+saveStateSuffix, state); err != nil {
+				log.Error(err)
+			}
+			mu.Lock()
 
-		for i := 0; i < opts.TPS; i++ {
-			if err := vm.Step(keyboard, screen, sound, timer); err != nil {
+		case EventLoadState:
+			mu.Unlock()
+			state, err := loadStateFromFile(filename + saveStateSuffix)
+			mu.Lock()
+			if err != nil {
+				log.Error(err)
+			} else if err := vm.Restore(state, keyboard, screen, sound, timer); err != nil {
 				log.Error(err)
 			}
 		}
 
+		if !paused {
+			sound.Step()
+			timer.Step()
+			vm.BeginFrame()
+		}
+
 		screen.Render()
+		mu.Unlock()
 	}
 
+	ticker.Stop()
+	<-cpuDone
+
 	return nil
 }
 
+func saveStateToFile(path string, state State) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return SaveState(file, state)
+}
+
+func loadStateFromFile(path string) (State, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return State{}, err
+	}
+	defer file.Close()
+
+	return LoadState(file)
+}
+
 func handleEvent(keyboard *Keyboard) Event {
 	if event := sdl.PollEvent(); event != nil {
 		switch event.GetType() {
@@ -163,6 +314,21 @@ func handleKeyEvent(event *sdl.KeyboardEvent, keyboard *Keyboard) Event {
 			return EventNextCycle
 		}
 
+	case sdl.SCANCODE_BACKSPACE:
+		if pressed {
+			return EventRewind
+		}
+
+	case sdl.SCANCODE_F5:
+		if pressed {
+			return EventSaveState
+		}
+
+	case sdl.SCANCODE_F9:
+		if pressed {
+			return EventLoadState
+		}
+
 	default:
 		if key, ok := scancodeMap[scancode]; ok {
 			keyboard.Set(key, pressed)
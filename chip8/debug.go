@@ -0,0 +1,99 @@
+package chip8
+
+// Debugging support for VirtualMachine: software breakpoints plus the
+// exported accessors a chip8/debug.Debugger needs to inspect state it
+// has no access to from outside the package.
+
+// SetBreakpoint marks addr so that AtBreakpoint reports true once the
+// program counter reaches it. Step itself does not check breakpoints;
+// the chip8/debug Debugger's Continue and RunToReturn stop there.
+func (vm *VirtualMachine) SetBreakpoint(addr uint16) {
+	if vm.breakpoints == nil {
+		vm.breakpoints = map[uint16]bool{}
+	}
+	vm.breakpoints[addr] = true
+}
+
+// ClearBreakpoint removes a previously set breakpoint.
+func (vm *VirtualMachine) ClearBreakpoint(addr uint16) {
+	delete(vm.breakpoints, addr)
+}
+
+// AtBreakpoint reports whether the instruction about to be fetched is
+// a breakpoint.
+func (vm *VirtualMachine) AtBreakpoint() bool {
+	return vm.breakpoints[vm.pc]
+}
+
+// PC returns the program counter.
+func (vm *VirtualMachine) PC() uint16 {
+	return vm.pc
+}
+
+// I returns the index register.
+func (vm *VirtualMachine) I() uint16 {
+	return vm.i
+}
+
+// SP returns the stack pointer.
+func (vm *VirtualMachine) SP() uint8 {
+	return vm.sp
+}
+
+// Registers returns a copy of V0..VF.
+func (vm *VirtualMachine) Registers() [0x10]uint8 {
+	return vm.v
+}
+
+// Stack returns a copy of the call stack.
+func (vm *VirtualMachine) Stack() [0x10]uint16 {
+	return vm.stack
+}
+
+// Mode returns the instruction set variant the virtual machine is
+// running in.
+func (vm *VirtualMachine) Mode() Mode {
+	return vm.mode
+}
+
+// MemoryAt returns a copy of n bytes of memory starting at addr,
+// truncated to fit within the address space.
+func (vm *VirtualMachine) MemoryAt(addr uint16, n int) []uint8 {
+	end := int(addr) + n
+	if end > len(vm.memory) {
+		end = len(vm.memory)
+	}
+	if int(addr) >= end {
+		return nil
+	}
+
+	out := make([]uint8, end-int(addr))
+	copy(out, vm.memory[addr:end])
+	return out
+}
+
+// StepOver executes one instruction, and if it was a CALL (2nnn),
+// keeps running until the matching RET brings the stack back to its
+// depth before the call.
+func (vm *VirtualMachine) StepOver(keyboard *Keyboard, screen *Screen, sound *Sound, timer *Timer) error {
+	isCall := vm.decodeAt(vm.pc)>>12 == 0x2
+	targetDepth := vm.sp
+
+	if err := vm.Step(keyboard, screen, sound, timer); err != nil {
+		return err
+	}
+	if !isCall {
+		return nil
+	}
+
+	for vm.sp > targetDepth {
+		if err := vm.Step(keyboard, screen, sound, timer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (vm *VirtualMachine) decodeAt(addr uint16) uint16 {
+	return uint16(vm.memory[addr])<<8 | uint16(vm.memory[addr+1])
+}
@@ -0,0 +1,208 @@
+package debug
+
+import (
+	"github.com/kevhlee/chip8/chip8"
+)
+
+// WatchKind distinguishes what a Watchpoint tracks.
+type WatchKind int
+
+const (
+	// WatchRegister watches one of V0..VF for writes.
+	WatchRegister WatchKind = iota
+
+	// WatchMemory watches a single memory address for writes.
+	WatchMemory
+)
+
+// Watchpoint is a register or memory location the Debugger reports a
+// hit for the first time its value changes after a step.
+type Watchpoint struct {
+	Kind WatchKind
+	Addr uint16
+}
+
+// RegisterDump is a snapshot of every CPU-visible register, for the
+// debugger's register inspection view.
+type RegisterDump struct {
+	V     [0x10]uint8
+	I     uint16
+	PC    uint16
+	SP    uint8
+	DT    uint8
+	ST    uint8
+	Stack [0x10]uint16
+}
+
+// Debugger wraps a VirtualMachine with the peripherals it steps
+// alongside, adding disassembly, state inspection, breakpoints, and
+// watchpoints on top of the plain Step/StepOver it already offers.
+type Debugger struct {
+	VM       *chip8.VirtualMachine
+	Keyboard *chip8.Keyboard
+	Screen   *chip8.Screen
+	Sound    *chip8.Sound
+	Timer    *chip8.Timer
+
+	watchpoints []Watchpoint
+	lastV       [0x10]uint8
+	lastMemory  map[uint16]uint8
+}
+
+// NewDebugger creates a Debugger around an already-constructed
+// VirtualMachine and the peripherals Step needs.
+func NewDebugger(vm *chip8.VirtualMachine, keyboard *chip8.Keyboard, screen *chip8.Screen, sound *chip8.Sound, timer *chip8.Timer) *Debugger {
+	return &Debugger{
+		VM:         vm,
+		Keyboard:   keyboard,
+		Screen:     screen,
+		Sound:      sound,
+		Timer:      timer,
+		lastV:      vm.Registers(),
+		lastMemory: map[uint16]uint8{},
+	}
+}
+
+// SetBreakpoint halts Continue/RunToReturn just before the
+// instruction at addr executes.
+func (d *Debugger) SetBreakpoint(addr uint16) {
+	d.VM.SetBreakpoint(addr)
+}
+
+// ClearBreakpoint removes a previously set breakpoint.
+func (d *Debugger) ClearBreakpoint(addr uint16) {
+	d.VM.ClearBreakpoint(addr)
+}
+
+// SetWatchpoint starts reporting writes to a register or memory
+// address as a hit from StepInstruction/StepOver/Continue.
+func (d *Debugger) SetWatchpoint(w Watchpoint) {
+	d.watchpoints = append(d.watchpoints, w)
+	if w.Kind == WatchMemory {
+		d.lastMemory[w.Addr] = d.VM.MemoryAt(w.Addr, 1)[0]
+	}
+}
+
+// ClearWatchpoints removes every watchpoint.
+func (d *Debugger) ClearWatchpoints() {
+	d.watchpoints = nil
+}
+
+// watchpointHits returns the watchpoints whose value changed since the
+// previous check, and updates the values later checks compare against.
+func (d *Debugger) watchpointHits() []Watchpoint {
+	var hits []Watchpoint
+	v := d.VM.Registers()
+
+	for _, w := range d.watchpoints {
+		switch w.Kind {
+		case WatchRegister:
+			if v[w.Addr] != d.lastV[w.Addr] {
+				hits = append(hits, w)
+			}
+		case WatchMemory:
+			b := d.VM.MemoryAt(w.Addr, 1)[0]
+			if b != d.lastMemory[w.Addr] {
+				hits = append(hits, w)
+				d.lastMemory[w.Addr] = b
+			}
+		}
+	}
+
+	d.lastV = v
+	return hits
+}
+
+// StepInstruction executes exactly one CPU cycle, ignoring any
+// breakpoint at the current PC, and returns any watchpoints it hit.
+func (d *Debugger) StepInstruction() ([]Watchpoint, error) {
+	if err := d.VM.Step(d.Keyboard, d.Screen, d.Sound, d.Timer); err != nil {
+		return nil, err
+	}
+	return d.watchpointHits(), nil
+}
+
+// StepOver executes one instruction, stepping over a CALL instead of
+// into it, and returns any watchpoints it hit.
+func (d *Debugger) StepOver() ([]Watchpoint, error) {
+	if err := d.VM.StepOver(d.Keyboard, d.Screen, d.Sound, d.Timer); err != nil {
+		return nil, err
+	}
+	return d.watchpointHits(), nil
+}
+
+// Continue runs instructions until a breakpoint is hit, a watchpoint
+// is hit, or an error occurs.
+func (d *Debugger) Continue() ([]Watchpoint, error) {
+	for {
+		if err := d.VM.Step(d.Keyboard, d.Screen, d.Sound, d.Timer); err != nil {
+			return nil, err
+		}
+		if hits := d.watchpointHits(); len(hits) > 0 {
+			return hits, nil
+		}
+		if d.VM.AtBreakpoint() {
+			return nil, nil
+		}
+	}
+}
+
+// RunToReturn keeps stepping until the current call frame returns
+// (the stack pointer drops below its depth when called), or a
+// breakpoint/watchpoint/error interrupts it first.
+func (d *Debugger) RunToReturn() ([]Watchpoint, error) {
+	targetDepth := d.VM.SP()
+	if targetDepth > 0 {
+		targetDepth--
+	}
+
+	for d.VM.SP() > targetDepth {
+		if err := d.VM.Step(d.Keyboard, d.Screen, d.Sound, d.Timer); err != nil {
+			return nil, err
+		}
+		if hits := d.watchpointHits(); len(hits) > 0 {
+			return hits, nil
+		}
+		if d.VM.AtBreakpoint() {
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
+// Registers returns a snapshot of every CPU-visible register.
+func (d *Debugger) Registers() RegisterDump {
+	return RegisterDump{
+		V:     d.VM.Registers(),
+		I:     d.VM.I(),
+		PC:    d.VM.PC(),
+		SP:    d.VM.SP(),
+		DT:    d.Timer.Read(),
+		ST:    d.Sound.Read(),
+		Stack: d.VM.Stack(),
+	}
+}
+
+// Disassemble decodes the n instructions starting at addr.
+func (d *Debugger) Disassemble(addr uint16, n int) []Instruction {
+	instructions := make([]Instruction, 0, n)
+
+	for i := 0; i < n; i++ {
+		mem := d.VM.MemoryAt(addr, 4)
+		if len(mem) == 0 {
+			break
+		}
+
+		instr := disassemble(mem, addr)
+		instructions = append(instructions, instr)
+		addr += uint16(instr.Width)
+	}
+
+	return instructions
+}
+
+// MemoryHex returns n bytes of memory starting at addr, for the
+// debugger's hex view.
+func (d *Debugger) MemoryHex(addr uint16, n int) []uint8 {
+	return d.VM.MemoryAt(addr, n)
+}
@@ -0,0 +1,169 @@
+// Package debug provides an interactive debugger for the chip8
+// package's VirtualMachine: disassembly, state inspection,
+// breakpoints, watchpoints, and step control, exposed over a
+// newline-delimited JSON socket so an external tool can drive it.
+package debug
+
+import "fmt"
+
+// Instruction is one disassembled opcode.
+type Instruction struct {
+	Addr     uint16
+	Opcode   uint16
+	Mnemonic string
+	Width    int
+}
+
+// disassemble decodes the instruction found in mem (which must start
+// at addr and hold at least 4 bytes, the widest instruction), without
+// needing access to a VirtualMachine.
+func disassemble(mem []uint8, addr uint16) Instruction {
+	opcode := word(mem, 0)
+	width := 2
+
+	x := uint8((opcode >> 8) & 0xF)
+	y := uint8((opcode >> 4) & 0xF)
+	n := uint8(opcode & 0xF)
+	nn := uint8(opcode & 0xFF)
+	nnn := opcode & 0xFFF
+
+	var mnemonic string
+
+	switch opcode >> 12 {
+	case 0x0:
+		switch {
+		case opcode == 0x00E0:
+			mnemonic = "CLS"
+		case opcode == 0x00EE:
+			mnemonic = "RET"
+		case opcode&0xFFF0 == 0x00C0:
+			mnemonic = fmt.Sprintf("SCD %d", n)
+		case opcode&0xFFF0 == 0x00D0:
+			mnemonic = fmt.Sprintf("SCU %d", n)
+		case opcode == 0x00FB:
+			mnemonic = "SCR"
+		case opcode == 0x00FC:
+			mnemonic = "SCL"
+		case opcode == 0x00FD:
+			mnemonic = "EXIT"
+		case opcode == 0x00FE:
+			mnemonic = "LOW"
+		case opcode == 0x00FF:
+			mnemonic = "HIGH"
+		default:
+			mnemonic = fmt.Sprintf("DW 0x%04X", opcode)
+		}
+	case 0x1:
+		mnemonic = fmt.Sprintf("JP 0x%03X", nnn)
+	case 0x2:
+		mnemonic = fmt.Sprintf("CALL 0x%03X", nnn)
+	case 0x3:
+		mnemonic = fmt.Sprintf("SE V%X, 0x%02X", x, nn)
+	case 0x4:
+		mnemonic = fmt.Sprintf("SNE V%X, 0x%02X", x, nn)
+	case 0x5:
+		switch n {
+		case 0x2:
+			mnemonic = fmt.Sprintf("LD [I], V%X..V%X", x, y)
+		case 0x3:
+			mnemonic = fmt.Sprintf("LD V%X..V%X, [I]", x, y)
+		default:
+			mnemonic = fmt.Sprintf("SE V%X, V%X", x, y)
+		}
+	case 0x6:
+		mnemonic = fmt.Sprintf("LD V%X, 0x%02X", x, nn)
+	case 0x7:
+		mnemonic = fmt.Sprintf("ADD V%X, 0x%02X", x, nn)
+	case 0x8:
+		switch n {
+		case 0x0:
+			mnemonic = fmt.Sprintf("LD V%X, V%X", x, y)
+		case 0x1:
+			mnemonic = fmt.Sprintf("OR V%X, V%X", x, y)
+		case 0x2:
+			mnemonic = fmt.Sprintf("AND V%X, V%X", x, y)
+		case 0x3:
+			mnemonic = fmt.Sprintf("XOR V%X, V%X", x, y)
+		case 0x4:
+			mnemonic = fmt.Sprintf("ADD V%X, V%X", x, y)
+		case 0x5:
+			mnemonic = fmt.Sprintf("SUB V%X, V%X", x, y)
+		case 0x6:
+			mnemonic = fmt.Sprintf("SHR V%X, {V%X}", x, y)
+		case 0x7:
+			mnemonic = fmt.Sprintf("SUBN V%X, V%X", x, y)
+		case 0xE:
+			mnemonic = fmt.Sprintf("SHL V%X, {V%X}", x, y)
+		default:
+			mnemonic = fmt.Sprintf("DW 0x%04X", opcode)
+		}
+	case 0x9:
+		mnemonic = fmt.Sprintf("SNE V%X, V%X", x, y)
+	case 0xA:
+		mnemonic = fmt.Sprintf("LD I, 0x%03X", nnn)
+	case 0xB:
+		mnemonic = fmt.Sprintf("JP V0, 0x%03X", nnn)
+	case 0xC:
+		mnemonic = fmt.Sprintf("RND V%X, 0x%02X", x, nn)
+	case 0xD:
+		mnemonic = fmt.Sprintf("DRW V%X, V%X, %d", x, y, n)
+	case 0xE:
+		switch nn {
+		case 0x9E:
+			mnemonic = fmt.Sprintf("SKP V%X", x)
+		case 0xA1:
+			mnemonic = fmt.Sprintf("SKNP V%X", x)
+		default:
+			mnemonic = fmt.Sprintf("DW 0x%04X", opcode)
+		}
+	case 0xF:
+		switch nn {
+		case 0x00:
+			if x == 0 && len(mem) >= 4 {
+				width = 4
+				mnemonic = fmt.Sprintf("LD I, 0x%04X", word(mem, 2))
+			} else {
+				mnemonic = fmt.Sprintf("DW 0x%04X", opcode)
+			}
+		case 0x01:
+			mnemonic = fmt.Sprintf("PLANE %d", x)
+		case 0x02:
+			mnemonic = "LD AUDIO, [I]"
+		case 0x07:
+			mnemonic = fmt.Sprintf("LD V%X, DT", x)
+		case 0x0A:
+			mnemonic = fmt.Sprintf("LD V%X, K", x)
+		case 0x15:
+			mnemonic = fmt.Sprintf("LD DT, V%X", x)
+		case 0x18:
+			mnemonic = fmt.Sprintf("LD ST, V%X", x)
+		case 0x1E:
+			mnemonic = fmt.Sprintf("ADD I, V%X", x)
+		case 0x29:
+			mnemonic = fmt.Sprintf("LD F, V%X", x)
+		case 0x33:
+			mnemonic = fmt.Sprintf("LD B, V%X", x)
+		case 0x3A:
+			mnemonic = fmt.Sprintf("PITCH V%X", x)
+		case 0x55:
+			mnemonic = fmt.Sprintf("LD [I], V%X", x)
+		case 0x65:
+			mnemonic = fmt.Sprintf("LD V%X, [I]", x)
+		case 0x75:
+			mnemonic = fmt.Sprintf("LD R, V%X", x)
+		case 0x85:
+			mnemonic = fmt.Sprintf("LD V%X, R", x)
+		default:
+			mnemonic = fmt.Sprintf("DW 0x%04X", opcode)
+		}
+	}
+
+	return Instruction{Addr: addr, Opcode: opcode, Mnemonic: mnemonic, Width: width}
+}
+
+func word(mem []uint8, offset int) uint16 {
+	if offset+1 >= len(mem) {
+		return 0
+	}
+	return uint16(mem[offset])<<8 | uint16(mem[offset+1])
+}
@@ -0,0 +1,140 @@
+package debug
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+)
+
+// request is a single line of the newline-delimited JSON protocol
+// Serve exposes, letting an external TUI drive the Debugger instead
+// of an SDL overlay panel.
+type request struct {
+	Cmd  string `json:"cmd"`
+	Addr uint16 `json:"addr,omitempty"`
+	Kind string `json:"kind,omitempty"`
+	N    int    `json:"n,omitempty"`
+}
+
+type response struct {
+	OK           bool          `json:"ok"`
+	Error        string        `json:"error,omitempty"`
+	Registers    *RegisterDump `json:"registers,omitempty"`
+	Instructions []Instruction `json:"instructions,omitempty"`
+	Memory       []uint8       `json:"memory,omitempty"`
+	Watchpoints  []Watchpoint  `json:"watchpoints,omitempty"`
+}
+
+// Serve listens on addr and accepts one newline-delimited JSON
+// command per line:
+//
+//	{"cmd":"break","addr":512}
+//	{"cmd":"clearBreak","addr":512}
+//	{"cmd":"watch","kind":"register","addr":3}
+//	{"cmd":"watch","kind":"memory","addr":512}
+//	{"cmd":"clearWatch"}
+//	{"cmd":"step"}
+//	{"cmd":"stepOver"}
+//	{"cmd":"runToReturn"}
+//	{"cmd":"continue"}
+//	{"cmd":"registers"}
+//	{"cmd":"disassemble","addr":512,"n":16}
+//	{"cmd":"memory","addr":512,"n":64}
+func Serve(addr string, d *Debugger) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go handleConn(conn, d)
+		}
+	}()
+
+	return nil
+}
+
+func handleConn(conn net.Conn, d *Debugger) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	encoder := json.NewEncoder(conn)
+
+	for scanner.Scan() {
+		var req request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			encoder.Encode(response{Error: err.Error()})
+			continue
+		}
+
+		resp := handle(req, d)
+		if err := encoder.Encode(resp); err != nil {
+			return
+		}
+	}
+}
+
+func handle(req request, d *Debugger) response {
+	switch req.Cmd {
+	case "break":
+		d.SetBreakpoint(req.Addr)
+	case "clearBreak":
+		d.ClearBreakpoint(req.Addr)
+	case "watch":
+		kind := WatchRegister
+		if req.Kind == "memory" {
+			kind = WatchMemory
+		}
+		d.SetWatchpoint(Watchpoint{Kind: kind, Addr: req.Addr})
+	case "clearWatch":
+		d.ClearWatchpoints()
+	case "step":
+		hits, err := d.StepInstruction()
+		if err != nil {
+			return response{Error: err.Error()}
+		}
+		return response{OK: true, Watchpoints: hits}
+	case "stepOver":
+		hits, err := d.StepOver()
+		if err != nil {
+			return response{Error: err.Error()}
+		}
+		return response{OK: true, Watchpoints: hits}
+	case "runToReturn":
+		hits, err := d.RunToReturn()
+		if err != nil {
+			return response{Error: err.Error()}
+		}
+		return response{OK: true, Watchpoints: hits}
+	case "continue":
+		hits, err := d.Continue()
+		if err != nil {
+			return response{Error: err.Error()}
+		}
+		return response{OK: true, Watchpoints: hits}
+	case "registers":
+		regs := d.Registers()
+		return response{OK: true, Registers: &regs}
+	case "disassemble":
+		n := req.N
+		if n <= 0 {
+			n = 16
+		}
+		return response{OK: true, Instructions: d.Disassemble(req.Addr, n)}
+	case "memory":
+		n := req.N
+		if n <= 0 {
+			n = 64
+		}
+		return response{OK: true, Memory: d.MemoryHex(req.Addr, n)}
+	default:
+		return response{Error: "unknown command: " + req.Cmd}
+	}
+
+	return response{OK: true}
+}
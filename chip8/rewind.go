@@ -0,0 +1,47 @@
+package chip8
+
+// Rewinder is a ring buffer of State snapshots, taken every interval
+// cycles, that backs a rewind hotkey. Older snapshots beyond capacity
+// are discarded.
+type Rewinder struct {
+	interval int
+	cycle    int
+	states   []State
+}
+
+// NewRewinder creates a Rewinder that snapshots every interval cycles
+// and keeps at most capacity of them.
+func NewRewinder(interval, capacity int) *Rewinder {
+	return &Rewinder{
+		interval: interval,
+		states:   make([]State, 0, capacity),
+	}
+}
+
+// Tick snapshots vm and its peripherals every interval cycles,
+// dropping the oldest snapshot once the buffer is at capacity. Call
+// this once per VirtualMachine.Step.
+func (r *Rewinder) Tick(vm *VirtualMachine, keyboard *Keyboard, screen *Screen, sound *Sound, timer *Timer) {
+	r.cycle++
+	if r.cycle%r.interval != 0 {
+		return
+	}
+
+	if len(r.states) == cap(r.states) {
+		copy(r.states, r.states[1:])
+		r.states = r.states[:len(r.states)-1]
+	}
+	r.states = append(r.states, vm.Snapshot(keyboard, screen, sound, timer))
+}
+
+// Pop removes and returns the most recent snapshot, along with
+// whether one was available.
+func (r *Rewinder) Pop() (State, bool) {
+	if len(r.states) == 0 {
+		return State{}, false
+	}
+
+	s := r.states[len(r.states)-1]
+	r.states = r.states[:len(r.states)-1]
+	return s, true
+}
@@ -3,73 +3,284 @@ package chip8
 import "github.com/veandco/go-sdl2/sdl"
 
 const (
-	// ScreenWidth is the width of the CHIP-8 screen.
+	// ScreenWidth is the width of the base CHIP-8 screen.
 	ScreenWidth = 0x40
 
-	// ScreenHeight is the height of the CHIP-8 screen.
+	// ScreenHeight is the height of the base CHIP-8 screen.
 	ScreenHeight = 0x20
 
-	// ScreenSize is the number of pixels in the CHIP-8 screen.
-	ScreenSize = ScreenWidth * ScreenHeight
+	// HiResScreenWidth is the width of the SUPER-CHIP/XO-CHIP hi-res
+	// screen.
+	HiResScreenWidth = 0x80
+
+	// HiResScreenHeight is the height of the SUPER-CHIP/XO-CHIP hi-res
+	// screen.
+	HiResScreenHeight = 0x40
+
+	// HiResScreenSize is the number of pixels in the hi-res screen.
+	HiResScreenSize = HiResScreenWidth * HiResScreenHeight
+
+	// NumPlanes is the number of bitplanes an XO-CHIP screen can draw
+	// to.
+	NumPlanes = 0x2
 )
 
-// Screen is the CHIP-8 screen.
+// Screen is the CHIP-8 screen. It always allocates hi-res-sized
+// bitplane buffers and tracks the active resolution separately, so
+// switching modes with 00FE/00FF doesn't need to reallocate.
 type Screen struct {
-	buffer   [ScreenSize]bool
+	planes   [NumPlanes][HiResScreenSize]bool
+	plane    uint8
+	hiRes    bool
+	wrap     bool
 	rect     *sdl.Rect
 	renderer *sdl.Renderer
 }
 
+// NewScreen creates a Screen that renders through renderer, with each
+// base-resolution pixel drawn as a scale x scale square.
 func NewScreen(renderer *sdl.Renderer, scale int32) *Screen {
 	return &Screen{
-		buffer:   [ScreenSize]bool{},
+		plane:    0x1,
+		wrap:     true,
 		rect:     &sdl.Rect{W: scale, H: scale},
 		renderer: renderer,
 	}
 }
 
+// SetWrapSprites controls whether sprites drawn partially off-screen
+// wrap around to the opposite edge (true, this package's historical
+// behavior) or are clipped instead (false, the WrapSprites=false
+// quirk).
+func (s *Screen) SetWrapSprites(wrap bool) {
+	s.wrap = wrap
+}
+
+// Width returns the screen's current width in pixels.
+func (s *Screen) Width() int {
+	if s.hiRes {
+		return HiResScreenWidth
+	}
+	return ScreenWidth
+}
+
+// Height returns the screen's current height in pixels.
+func (s *Screen) Height() int {
+	if s.hiRes {
+		return HiResScreenHeight
+	}
+	return ScreenHeight
+}
+
+// SetHiRes switches between the base 64x32 resolution and the
+// SUPER-CHIP/XO-CHIP 128x64 hi-res resolution, clearing the screen.
+func (s *Screen) SetHiRes(hiRes bool) {
+	s.hiRes = hiRes
+	s.Clear()
+}
+
+// SetPlane selects which of the two XO-CHIP bitplanes subsequent
+// SetSprite calls draw into, as a bitmask (bit 0 = plane 0, bit 1 =
+// plane 1). CHIP-8/SCHIP programs always draw to plane 0.
+func (s *Screen) SetPlane(mask uint8) {
+	s.plane = mask & 0x3
+}
+
+// Render draws both bitplanes (OR-combined) to the renderer,
+// scaling each logical pixel up by the configured scale factor.
 func (s *Screen) Render() {
 	s.renderer.SetDrawColor(0x00, 0x00, 0x00, 0xFF)
 	s.renderer.Clear()
 	s.renderer.SetDrawColor(0xFF, 0xFF, 0xFF, 0xFF)
 
-	for i, on := range s.buffer {
-		if on {
-			s.rect.X = int32(i%ScreenWidth) * s.rect.W
-			s.rect.Y = int32(i/ScreenWidth) * s.rect.H
-			s.renderer.FillRect(s.rect)
+	width := s.Width()
+	scale := s.rect.W
+
+	for i := 0; i < width*s.Height(); i++ {
+		if !s.planes[0][i] && !s.planes[1][i] {
+			continue
 		}
+
+		s.rect.X = int32(i%width) * scale
+		s.rect.Y = int32(i/width) * scale
+		s.renderer.FillRect(s.rect)
 	}
 
 	s.renderer.Present()
 }
 
+// Clear clears every bitplane.
 func (s *Screen) Clear() {
-	for i := 0; i < len(s.buffer); i++ {
-		s.buffer[i] = false
+	for p := range s.planes {
+		for i := range s.planes[p] {
+			s.planes[p][i] = false
+		}
 	}
 }
 
+// SetSprite XORs an 8-pixel-wide sprite into the selected bitplanes
+// at (x, y), reporting whether any pixel was erased (collision).
 func (s *Screen) SetSprite(x, y uint8, sprite ...uint8) bool {
 	flag := false
 
-	for i, b := range sprite {
-		for j := uint8(0); j < 8; j++ {
-			idx := s.getBufferIndex(x+(7-j), y+uint8(i))
-			bit := b&1 == 1
+	for p := 0; p < NumPlanes; p++ {
+		if s.plane&(1<<p) == 0 {
+			continue
+		}
+
+		for i, b := range sprite {
+			for j := 0; j < 8; j++ {
+				idx, ok := s.pixelIndex(int(x)+(7-j), int(y)+i)
+				bit := b&1 == 1
 
-			if s.buffer[idx] && bit {
-				flag = true
+				if ok {
+					if s.planes[p][idx] && bit {
+						flag = true
+					}
+					s.planes[p][idx] = s.planes[p][idx] != bit
+				}
+				b >>= 1
 			}
+		}
+	}
 
-			s.buffer[idx] = s.buffer[idx] != bit
-			b >>= 1
+	return flag
+}
+
+// SetBigSprite XORs a 16x16 SUPER-CHIP/XO-CHIP big sprite into the
+// selected bitplanes at (x, y), reporting whether any pixel was
+// erased (collision).
+func (s *Screen) SetBigSprite(x, y uint8, sprite []uint8) bool {
+	flag := false
+
+	for p := 0; p < NumPlanes; p++ {
+		if s.plane&(1<<p) == 0 {
+			continue
+		}
+
+		for row := 0; row < 16; row++ {
+			word := uint16(sprite[row*2])<<8 | uint16(sprite[row*2+1])
+
+			for col := 0; col < 16; col++ {
+				if word&(1<<(15-col)) == 0 {
+					continue
+				}
+
+				idx, ok := s.pixelIndex(int(x)+col, int(y)+row)
+				if !ok {
+					continue
+				}
+				if s.planes[p][idx] {
+					flag = true
+				}
+				s.planes[p][idx] = !s.planes[p][idx]
+			}
 		}
 	}
 
 	return flag
 }
 
-func (s *Screen) getBufferIndex(x, y uint8) int {
-	return (int(y%ScreenHeight) * ScreenWidth) + int(x%ScreenWidth)
+// ScrollDown shifts every selected bitplane down by n pixels,
+// filling the vacated rows with off pixels.
+func (s *Screen) ScrollDown(n int) {
+	s.eachSelectedPlane(func(plane *[HiResScreenSize]bool) {
+		width, height := s.Width(), s.Height()
+
+		for y := height - 1; y >= 0; y-- {
+			for x := 0; x < width; x++ {
+				src := y - n
+				idx := y*width + x
+				if src >= 0 {
+					plane[idx] = plane[src*width+x]
+				} else {
+					plane[idx] = false
+				}
+			}
+		}
+	})
+}
+
+// ScrollUp shifts every selected bitplane up by n pixels, an XO-CHIP
+// extension (00DN).
+func (s *Screen) ScrollUp(n int) {
+	s.eachSelectedPlane(func(plane *[HiResScreenSize]bool) {
+		width, height := s.Width(), s.Height()
+
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				src := y + n
+				idx := y*width + x
+				if src < height {
+					plane[idx] = plane[src*width+x]
+				} else {
+					plane[idx] = false
+				}
+			}
+		}
+	})
+}
+
+// ScrollLeft shifts every selected bitplane left by 4 pixels (00FC).
+func (s *Screen) ScrollLeft() {
+	s.scrollHorizontal(4, false)
+}
+
+// ScrollRight shifts every selected bitplane right by 4 pixels
+// (00FB).
+func (s *Screen) ScrollRight() {
+	s.scrollHorizontal(4, true)
+}
+
+func (s *Screen) scrollHorizontal(n int, right bool) {
+	s.eachSelectedPlane(func(plane *[HiResScreenSize]bool) {
+		width, height := s.Width(), s.Height()
+
+		for y := 0; y < height; y++ {
+			row := y * width
+
+			if right {
+				for x := width - 1; x >= 0; x-- {
+					src := x - n
+					if src >= 0 {
+						plane[row+x] = plane[row+src]
+					} else {
+						plane[row+x] = false
+					}
+				}
+			} else {
+				for x := 0; x < width; x++ {
+					src := x + n
+					if src < width {
+						plane[row+x] = plane[row+src]
+					} else {
+						plane[row+x] = false
+					}
+				}
+			}
+		}
+	})
+}
+
+func (s *Screen) eachSelectedPlane(fn func(plane *[HiResScreenSize]bool)) {
+	for p := 0; p < NumPlanes; p++ {
+		if s.plane&(1<<p) != 0 {
+			fn(&s.planes[p])
+		}
+	}
+}
+
+// pixelIndex converts a possibly out-of-bounds (x, y) into a buffer
+// index, wrapping or clipping depending on SetWrapSprites. ok is
+// false when the pixel is clipped off-screen.
+func (s *Screen) pixelIndex(x, y int) (idx int, ok bool) {
+	width, height := s.Width(), s.Height()
+
+	if s.wrap {
+		return ((y%height+height)%height)*width + (x%width+width)%width, true
+	}
+	if x < 0 || x >= width || y < 0 || y >= height {
+		return 0, false
+	}
+	return y*width + x, true
 }
@@ -0,0 +1,127 @@
+package chip8
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+)
+
+// StateVersion is incremented whenever State's layout changes, so
+// LoadState can reject a save file encoded by an incompatible build
+// instead of decoding it into garbage.
+const StateVersion = 1
+
+// State is a serializable capture of a complete emulator session: the
+// virtual machine, the keyboard's polling state, and the screen
+// buffer. Used for save states and for the Rewinder's history.
+type State struct {
+	Version int
+
+	Mode   Mode
+	Quirks Quirks
+	I      uint16
+	SP     uint8
+	PC     uint16
+	Memory [0x1000]uint8
+	V      [0x10]uint8
+	Stack  [0x10]uint16
+	RPL    [0x10]uint8
+
+	DT uint8
+	ST uint8
+
+	Keys    [NumKeys]bool
+	Polling bool
+	LastKey int
+
+	Display [NumPlanes][HiResScreenSize]bool
+	Plane   uint8
+	HiRes   bool
+	Wrap    bool
+
+	RNGSeed int64
+}
+
+// Snapshot captures the state of vm and the peripherals it steps
+// alongside. The Sound's XO-CHIP audio pattern and pitch, and the
+// pending display interrupt set by BeginFrame, are not captured.
+func (vm *VirtualMachine) Snapshot(keyboard *Keyboard, screen *Screen, sound *Sound, timer *Timer) State {
+	return State{
+		Version: StateVersion,
+
+		Mode:   vm.mode,
+		Quirks: vm.quirks,
+		I:      vm.i,
+		SP:     vm.sp,
+		PC:     vm.pc,
+		Memory: vm.memory,
+		V:      vm.v,
+		Stack:  vm.stack,
+		RPL:    vm.rpl,
+
+		DT: timer.value,
+		ST: sound.value,
+
+		Keys:    keyboard.keys,
+		Polling: keyboard.polling,
+		LastKey: keyboard.lastKey,
+
+		Display: screen.planes,
+		Plane:   screen.plane,
+		HiRes:   screen.hiRes,
+		Wrap:    screen.wrap,
+
+		RNGSeed: vm.rngSeed,
+	}
+}
+
+// Restore resets vm and the peripherals to a previously captured
+// State.
+func (vm *VirtualMachine) Restore(s State, keyboard *Keyboard, screen *Screen, sound *Sound, timer *Timer) error {
+	if s.Version != StateVersion {
+		return fmt.Errorf("unsupported save state version: %d", s.Version)
+	}
+
+	vm.mode = s.Mode
+	vm.quirks = s.Quirks
+	vm.i = s.I
+	vm.sp = s.SP
+	vm.pc = s.PC
+	vm.memory = s.Memory
+	vm.v = s.V
+	vm.stack = s.Stack
+	vm.rpl = s.RPL
+	vm.SeedRand(s.RNGSeed)
+
+	timer.Write(s.DT)
+	sound.Write(s.ST)
+
+	keyboard.keys = s.Keys
+	keyboard.polling = s.Polling
+	keyboard.lastKey = s.LastKey
+
+	screen.planes = s.Display
+	screen.plane = s.Plane
+	screen.hiRes = s.HiRes
+	screen.wrap = s.Wrap
+
+	return nil
+}
+
+// SaveState gob-encodes a State to w.
+func SaveState(w io.Writer, s State) error {
+	return gob.NewEncoder(w).Encode(s)
+}
+
+// LoadState gob-decodes a State from r, rejecting one saved by an
+// incompatible StateVersion.
+func LoadState(r io.Reader) (State, error) {
+	var s State
+	if err := gob.NewDecoder(r).Decode(&s); err != nil {
+		return State{}, err
+	}
+	if s.Version != StateVersion {
+		return State{}, fmt.Errorf("unsupported save state version: %d", s.Version)
+	}
+	return s, nil
+}
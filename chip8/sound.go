@@ -1,19 +1,81 @@
 package chip8
 
+// Audio is the interface the core virtual machine speaks through to
+// produce sound, letting the frontend own the concrete audio device
+// (an SDL one, or a silent mock for headless/test builds).
+type Audio interface {
+	Start() error
+	Stop() error
+	SetPattern(pattern [AudioPatternSize]uint8, pitch float64)
+}
+
+// Sound is the CHIP-8 sound timer. While its value is above zero, it
+// plays through audio, which may be nil to run without sound.
 type Sound struct {
 	value uint8
+	audio Audio
+
+	pattern [AudioPatternSize]uint8
+	pitch   float64
 }
 
-func NewSound() *Sound {
-	return &Sound{}
+func NewSound(audio Audio) *Sound {
+	return &Sound{audio: audio, pitch: DefaultPitch}
+}
+
+func (s Sound) Read() uint8 {
+	return s.value
 }
 
 func (s *Sound) Write(value uint8) {
+	wasPlaying := s.value > 0
 	s.value = value
+
+	if s.audio == nil {
+		return
+	}
+	if value > 0 && !wasPlaying {
+		s.audio.Start()
+	} else if value == 0 && wasPlaying {
+		s.audio.Stop()
+	}
 }
 
 func (s *Sound) Step() {
-	if s.value > 0 {
-		s.value--
+	if s.value == 0 {
+		return
+	}
+
+	s.value--
+	if s.value == 0 && s.audio != nil {
+		s.audio.Stop()
+	}
+}
+
+// SetAudioPattern updates the XO-CHIP audio pattern buffer (Fx02),
+// forwarding the change to audio if it actually changed.
+func (s *Sound) SetAudioPattern(pattern [AudioPatternSize]uint8) {
+	if pattern == s.pattern {
+		return
+	}
+
+	s.pattern = pattern
+	s.notifyAudio()
+}
+
+// SetPitch updates the XO-CHIP playback pitch (Fx3A), forwarding the
+// change to audio if it actually changed.
+func (s *Sound) SetPitch(pitch float64) {
+	if pitch == s.pitch {
+		return
+	}
+
+	s.pitch = pitch
+	s.notifyAudio()
+}
+
+func (s *Sound) notifyAudio() {
+	if s.audio != nil {
+		s.audio.SetPattern(s.pattern, s.pitch)
 	}
 }
@@ -0,0 +1,158 @@
+package chip8
+
+import "time"
+
+// Ticker paces the CPU loop, deciding when the virtual machine should
+// fetch and execute its next instruction. Driving the CPU loop off a
+// Ticker instead of embedding a fixed schedule inline in Start is what
+// lets pausing, single-stepping, and audio timing stay deterministic
+// and testable independent of SDL.
+type Ticker interface {
+	// Wait blocks until the next cycle should run, returning false if
+	// the ticker has been stopped.
+	Wait() bool
+
+	// Stop releases the ticker's resources. Every blocked and future
+	// call to Wait returns false afterward.
+	Stop()
+}
+
+// FrameTicker runs cyclesPerFrame cycles every time a 60Hz frame
+// elapses, the fixed schedule Start originally ran inline.
+type FrameTicker struct {
+	cyclesPerFrame int
+	left           int
+	ticker         *time.Ticker
+	done           chan struct{}
+}
+
+// NewFrameTicker creates a FrameTicker that releases cyclesPerFrame
+// Wait calls every 60Hz frame.
+func NewFrameTicker(cyclesPerFrame int) *FrameTicker {
+	return &FrameTicker{
+		cyclesPerFrame: cyclesPerFrame,
+		ticker:         time.NewTicker(time.Second / 60),
+		done:           make(chan struct{}),
+	}
+}
+
+func (t *FrameTicker) Wait() bool {
+	select {
+	case <-t.done:
+		return false
+	default:
+	}
+
+	for t.left == 0 {
+		select {
+		case <-t.ticker.C:
+			t.left = t.cyclesPerFrame
+		case <-t.done:
+			return false
+		}
+	}
+
+	t.left--
+	return true
+}
+
+// Stop implements Ticker.
+func (t *FrameTicker) Stop() {
+	t.ticker.Stop()
+	close(t.done)
+}
+
+// HzTicker runs cycles at a target rate, catching up with a burst of
+// immediately-ready cycles if a Wait call runs late instead of
+// letting the clock drift.
+type HzTicker struct {
+	period time.Duration
+	next   time.Time
+	done   chan struct{}
+}
+
+// NewHzTicker creates an HzTicker targeting hz cycles per second.
+func NewHzTicker(hz int) *HzTicker {
+	period := time.Second / time.Duration(hz)
+	return &HzTicker{
+		period: period,
+		next:   time.Now().Add(period),
+		done:   make(chan struct{}),
+	}
+}
+
+func (t *HzTicker) Wait() bool {
+	select {
+	case <-t.done:
+		return false
+	default:
+	}
+
+	if now := time.Now(); now.Before(t.next) {
+		timer := time.NewTimer(t.next.Sub(now))
+		select {
+		case <-timer.C:
+		case <-t.done:
+			timer.Stop()
+			return false
+		}
+	}
+
+	t.next = t.next.Add(t.period)
+	if t.next.Before(time.Now()) {
+		// Fell behind by more than a full period (e.g. the process was
+		// suspended): resync instead of spinning through an unbounded
+		// catch-up burst.
+		t.next = time.Now().Add(t.period)
+	}
+
+	return true
+}
+
+// Stop implements Ticker.
+func (t *HzTicker) Stop() {
+	close(t.done)
+}
+
+// ManualTicker only runs a cycle when told to via Step, for tests and
+// the debugger's single-step command.
+type ManualTicker struct {
+	step chan struct{}
+	done chan struct{}
+}
+
+// NewManualTicker creates a ManualTicker.
+func NewManualTicker() *ManualTicker {
+	return &ManualTicker{
+		step: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+}
+
+func (t *ManualTicker) Wait() bool {
+	select {
+	case <-t.step:
+		return true
+	case <-t.done:
+		return false
+	}
+}
+
+// Step unblocks exactly one pending (or the next) call to Wait.
+func (t *ManualTicker) Step() {
+	select {
+	case t.step <- struct{}{}:
+	case <-t.done:
+	}
+}
+
+// Stop implements Ticker.
+func (t *ManualTicker) Stop() {
+	close(t.done)
+}
+
+var (
+	_ Ticker = (*FrameTicker)(nil)
+	_ Ticker = (*HzTicker)(nil)
+	_ Ticker = (*ManualTicker)(nil)
+)
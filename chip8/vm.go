@@ -1,31 +1,65 @@
 package chip8
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"math/rand"
+	"time"
 )
 
 const (
 	// ProgramStartAddress is the start memory address for CHIP-8 programs.
 	ProgramStartAddress = 0x200
+
+	// BigSpriteSize is the number of bytes a SUPER-CHIP/XO-CHIP 16x16
+	// big sprite (Dxy0) occupies in memory.
+	BigSpriteSize = 0x20
+
+	// AudioPatternSize is the number of bytes in an XO-CHIP audio
+	// pattern buffer (Fx02).
+	AudioPatternSize = 0x10
 )
 
+// ErrHalted is returned by Step when the program executes a
+// SUPER-CHIP EXIT opcode (00FD).
+var ErrHalted = errors.New("program halted")
+
 // VirtualMachine is the CHIP-8 virtual machine.
 type VirtualMachine struct {
+	mode   Mode
+	quirks Quirks
 	i      uint16
 	sp     uint8
 	pc     uint16
 	memory [0x1000]uint8
 	v      [0x10]uint8
 	stack  [0x10]uint16
+	rpl    [0x10]uint8
+
+	vblank      bool
+	breakpoints map[uint16]bool
+
+	rng     *rand.Rand
+	rngSeed int64
 }
 
-// NewVirtualMachine creates a CHIP-8 new virtual machine.
-func NewVirtualMachine() *VirtualMachine {
+// NewVirtualMachine creates a CHIP-8 new virtual machine running in
+// the given mode, with DefaultQuirks.
+func NewVirtualMachine(mode Mode) *VirtualMachine {
+	return NewVirtualMachineWithQuirks(mode, DefaultQuirks)
+}
+
+// NewVirtualMachineWithQuirks creates a CHIP-8 new virtual machine
+// running in the given mode, with platform-specific opcode semantics
+// selected by quirks.
+func NewVirtualMachineWithQuirks(mode Mode, quirks Quirks) *VirtualMachine {
 	m := &VirtualMachine{
-		pc:    ProgramStartAddress,
-		v:     [0x10]uint8{},
-		stack: [0x10]uint16{},
+		mode:   mode,
+		quirks: quirks,
+		pc:     ProgramStartAddress,
+		v:      [0x10]uint8{},
+		stack:  [0x10]uint16{},
 	}
 
 	m.memory = [0x1000]uint8{
@@ -47,9 +81,18 @@ func NewVirtualMachine() *VirtualMachine {
 		0xF0, 0x80, 0xF0, 0x80, 0x80, // F
 	}
 
+	m.SeedRand(time.Now().UnixNano())
 	return m
 }
 
+// SeedRand reseeds the Cxkk random number generator, making
+// subsequent RND results deterministic. Used to restore a Snapshot
+// exactly and to drive reproducible tests.
+func (vm *VirtualMachine) SeedRand(seed int64) {
+	vm.rngSeed = seed
+	vm.rng = rand.New(rand.NewSource(seed))
+}
+
 // LoadProgram loads bytes into memory.
 func (vm *VirtualMachine) LoadProgram(bytes ...byte) error {
 	if len(bytes) >= (len(vm.memory) - ProgramStartAddress) {
@@ -77,6 +120,13 @@ func (vm *VirtualMachine) Reset() {
 	}
 }
 
+// BeginFrame marks a display interrupt as having occurred, letting one
+// Dxyn opcode draw under the DrawWaitsForVBlank quirk before it blocks
+// again. Callers should invoke this once per display refresh.
+func (vm *VirtualMachine) BeginFrame() {
+	vm.vblank = true
+}
+
 // Step executes a single CPU cycle.
 func (vm *VirtualMachine) Step(keyboard *Keyboard, screen *Screen, sound *Sound, timer *Timer) error {
 	opcode := vm.fetchOpcode()
@@ -113,7 +163,7 @@ func (vm *VirtualMachine) Step(keyboard *Keyboard, screen *Screen, sound *Sound,
 	case 0xE:
 		return vm.executeOpE(opcode, keyboard)
 	case 0xF:
-		return vm.executeOpF(opcode, keyboard, sound, timer)
+		return vm.executeOpF(opcode, keyboard, screen, sound, timer)
 	default:
 		// Unreachable
 		return nil
@@ -126,6 +176,18 @@ func (vm *VirtualMachine) fetchOpcode() uint16 {
 	return opcode
 }
 
+// skip advances pc past the next instruction, as used by the various
+// SE/SNE/SKP/SKNP opcodes. It accounts for F000 NNNN, the one
+// XO-CHIP instruction that's 4 bytes wide instead of 2.
+func (vm *VirtualMachine) skip() {
+	next := (uint16(vm.memory[vm.pc]) << 8) | uint16(vm.memory[vm.pc+1])
+	if next == 0xF000 {
+		vm.pc += 4
+	} else {
+		vm.pc += 2
+	}
+}
+
 func decodeX(opcode uint16) uint8 {
 	return uint8((opcode >> 8) & 0xF)
 }
@@ -147,19 +209,47 @@ func decodeNibb(opcode uint16) uint8 {
 }
 
 func (vm *VirtualMachine) executeOp0(opcode uint16, screen *Screen) error {
-	switch opcode {
+	switch {
 	// 00E0 - CLS
-	case 0x00E0:
+	case opcode == 0x00E0:
 		screen.Clear()
 
 	// 00EE - RET
-	case 0x00EE:
+	case opcode == 0x00EE:
 		if vm.sp < 1 {
 			return fmt.Errorf("Empty call stack")
 		}
 
 		vm.sp--
 		vm.pc = vm.stack[vm.sp]
+
+	// 00Cn - SCD n (SUPER-CHIP)
+	case opcode&0xFFF0 == 0x00C0:
+		screen.ScrollDown(int(decodeNibb(opcode)))
+
+	// 00Dn - SCU n (XO-CHIP)
+	case opcode&0xFFF0 == 0x00D0:
+		screen.ScrollUp(int(decodeNibb(opcode)))
+
+	// 00FB - SCR (SUPER-CHIP)
+	case opcode == 0x00FB:
+		screen.ScrollRight()
+
+	// 00FC - SCL (SUPER-CHIP)
+	case opcode == 0x00FC:
+		screen.ScrollLeft()
+
+	// 00FD - EXIT (SUPER-CHIP)
+	case opcode == 0x00FD:
+		return ErrHalted
+
+	// 00FE - LOW (SUPER-CHIP)
+	case opcode == 0x00FE:
+		screen.SetHiRes(false)
+
+	// 00FF - HIGH (SUPER-CHIP)
+	case opcode == 0x00FF:
+		screen.SetHiRes(true)
 	}
 
 	return nil
@@ -186,7 +276,7 @@ func (vm *VirtualMachine) executeOp2(opcode uint16) error {
 func (vm *VirtualMachine) executeOp3(opcode uint16) error {
 	// 3xkk - SE Vx, byte
 	if vm.v[decodeX(opcode)] == decodeByte(opcode) {
-		vm.pc += 2
+		vm.skip()
 	}
 	return nil
 }
@@ -194,16 +284,42 @@ func (vm *VirtualMachine) executeOp3(opcode uint16) error {
 func (vm *VirtualMachine) executeOp4(opcode uint16) error {
 	// 4xkk - SNE Vx, byte
 	if vm.v[decodeX(opcode)] != decodeByte(opcode) {
-		vm.pc += 2
+		vm.skip()
 	}
 	return nil
 }
 
 func (vm *VirtualMachine) executeOp5(opcode uint16) error {
+	x, y := decodeX(opcode), decodeY(opcode)
+
+	switch decodeNibb(opcode) {
 	// 5xy0 - SE Vx, Vy
-	if decodeNibb(opcode) == 0 && vm.v[decodeX(opcode)] == vm.v[decodeY(opcode)] {
-		vm.pc += 2
+	case 0x0:
+		if vm.v[x] == vm.v[y] {
+			vm.skip()
+		}
+
+	// 5xy2 - LD [I], Vx..Vy (XO-CHIP)
+	case 0x2:
+		lo, hi := x, y
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		for i := uint16(0); i <= uint16(hi-lo); i++ {
+			vm.memory[vm.i+i] = vm.v[lo+uint8(i)]
+		}
+
+	// 5xy3 - LD Vx..Vy, [I] (XO-CHIP)
+	case 0x3:
+		lo, hi := x, y
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		for i := uint16(0); i <= uint16(hi-lo); i++ {
+			vm.v[lo+uint8(i)] = vm.memory[vm.i+i]
+		}
 	}
+
 	return nil
 }
 
@@ -230,51 +346,72 @@ func (vm *VirtualMachine) executeOp8(opcode uint16) error {
 	// 8xy1 - OR Vx, Vy
 	case 0x1:
 		vm.v[x] |= vm.v[y]
+		if vm.quirks.LogicResetsVF {
+			vm.v[0xF] = 0
+		}
 
 	// 8xy2 - AND Vx, Vy
 	case 0x2:
 		vm.v[x] &= vm.v[y]
+		if vm.quirks.LogicResetsVF {
+			vm.v[0xF] = 0
+		}
 
 	// 8xy3 - XOR Vx, Vy
 	case 0x3:
 		vm.v[x] ^= vm.v[y]
+		if vm.quirks.LogicResetsVF {
+			vm.v[0xF] = 0
+		}
 
 	// 8xy4 - ADD Vx, Vy
 	case 0x4:
+		var flag uint8
 		if vm.v[x] > 0xFF-vm.v[y] {
-			vm.v[0xF] = 1
-		} else {
-			vm.v[0xF] = 0
+			flag = 1
 		}
 		vm.v[x] += vm.v[y]
+		vm.v[0xF] = flag
 
 	// 8xy5 - SUB Vx, Vy
 	case 0x5:
+		var flag uint8
 		if vm.v[x] > vm.v[y] {
-			vm.v[0xF] = 1
-		} else {
-			vm.v[0xF] = 0
+			flag = 1
 		}
 		vm.v[x] -= vm.v[y]
+		vm.v[0xF] = flag
 
 	// 8xy6 - SHR Vx, {Vy}
 	case 0x6:
-		vm.v[0xF] = vm.v[x] & 1
-		vm.v[x] >>= 1
+		src := vm.v[x]
+		if vm.quirks.ShiftUsesVy {
+			src = vm.v[y]
+		}
+
+		flag := src & 1
+		vm.v[x] = src >> 1
+		vm.v[0xF] = flag
 
 	// 8xy7 - SUBN Vx, Vy
 	case 0x7:
+		var flag uint8
 		if vm.v[y] > vm.v[x] {
-			vm.v[0xF] = 1
-		} else {
-			vm.v[0xF] = 0
+			flag = 1
 		}
 		vm.v[x] = vm.v[y] - vm.v[x]
+		vm.v[0xF] = flag
 
 	// 8xyE - SHL Vx, {Vy}
 	case 0xE:
-		vm.v[0xF] = vm.v[x] >> 7
-		vm.v[x] <<= 1
+		src := vm.v[x]
+		if vm.quirks.ShiftUsesVy {
+			src = vm.v[y]
+		}
+
+		flag := src >> 7
+		vm.v[x] = src << 1
+		vm.v[0xF] = flag
 	}
 
 	return nil
@@ -283,7 +420,7 @@ func (vm *VirtualMachine) executeOp8(opcode uint16) error {
 func (vm *VirtualMachine) executeOp9(opcode uint16) error {
 	// 9xy0 - SNE Vx, Vy
 	if decodeNibb(opcode) == 0 && vm.v[decodeX(opcode)] != vm.v[decodeY(opcode)] {
-		vm.pc += 2
+		vm.skip()
 	}
 	return nil
 }
@@ -295,23 +432,41 @@ func (vm *VirtualMachine) executeOpA(opcode uint16) error {
 }
 
 func (vm *VirtualMachine) executeOpB(opcode uint16) error {
-	// Bnnn - JP addr, V0
-	vm.pc = decodeAddr(opcode) + uint16(vm.v[0])
+	// Bnnn - JP addr, V0 (Bxnn - JP addr, Vx under the JumpUsesVx quirk)
+	reg := uint8(0)
+	if vm.quirks.JumpUsesVx {
+		reg = decodeX(opcode)
+	}
+	vm.pc = decodeAddr(opcode) + uint16(vm.v[reg])
 	return nil
 }
 
 func (vm *VirtualMachine) executeOpC(opcode uint16) error {
 	// Cxkk - RND Vx, byte
-	vm.v[decodeX(opcode)] = uint8(rand.Intn(0x100)) & decodeByte(opcode)
+	vm.v[decodeX(opcode)] = uint8(vm.rng.Intn(0x100)) & decodeByte(opcode)
 	return nil
 }
 
 func (vm *VirtualMachine) executeOpD(opcode uint16, screen *Screen) error {
-	// Dxyn - DRW Vx, Vy, nibb
+	// Dxyn - DRW Vx, Vy, nibb (Dxy0 is a 16x16 big sprite in SCHIP/XO-CHIP)
+	if vm.quirks.DrawWaitsForVBlank && !vm.vblank {
+		vm.pc -= 2
+		return nil
+	}
+	vm.vblank = false
+
 	vx := vm.v[decodeX(opcode)]
 	vy := vm.v[decodeY(opcode)]
+	n := decodeNibb(opcode)
+
+	var collision bool
+	if n == 0 && vm.mode != ModeChip8 {
+		collision = screen.SetBigSprite(vx, vy, vm.memory[vm.i:vm.i+BigSpriteSize])
+	} else {
+		collision = screen.SetSprite(vx, vy, vm.memory[vm.i:vm.i+uint16(n)]...)
+	}
 
-	if screen.SetSprite(vx, vy, vm.memory[vm.i:vm.i+uint16(decodeNibb(opcode))]...) {
+	if collision {
 		vm.v[0xF] = 1
 	} else {
 		vm.v[0xF] = 0
@@ -327,23 +482,36 @@ func (vm *VirtualMachine) executeOpE(opcode uint16, keyboard *Keyboard) error {
 	// Ex9E - SKP Vx
 	case 0x9E:
 		if keyboard.IsPressed(vm.v[x]) {
-			vm.pc += 2
+			vm.skip()
 		}
 
 	// ExA1 - SKNP Vx
 	case 0xA1:
 		if !keyboard.IsPressed(vm.v[x]) {
-			vm.pc += 2
+			vm.skip()
 		}
 	}
 
 	return nil
 }
 
-func (vm *VirtualMachine) executeOpF(opcode uint16, keyboard *Keyboard, sound *Sound, timer *Timer) error {
+func (vm *VirtualMachine) executeOpF(opcode uint16, keyboard *Keyboard, screen *Screen, sound *Sound, timer *Timer) error {
 	x := decodeX(opcode)
 
 	switch decodeByte(opcode) {
+	// Fn01 - plane n (XO-CHIP); here x is the plane bitmask, not a
+	// register index.
+	case 0x01:
+		screen.SetPlane(x)
+
+	// Fx02 - audio pattern buffer (XO-CHIP)
+	case 0x02:
+		var pattern [AudioPatternSize]uint8
+		for i := 0; i < AudioPatternSize; i++ {
+			pattern[i] = vm.memory[vm.i+uint16(i)]
+		}
+		sound.SetAudioPattern(pattern)
+
 	// Fx07 - LD Vx, DT
 	case 0x07:
 		vm.v[x] = timer.Read()
@@ -378,17 +546,45 @@ func (vm *VirtualMachine) executeOpF(opcode uint16, keyboard *Keyboard, sound *S
 		vm.memory[vm.i+1] = (vm.v[x] % 100) / 10
 		vm.memory[vm.i+2] = vm.v[x] % 10
 
+	// Fx3A - PITCH Vx (XO-CHIP)
+	case 0x3A:
+		sound.SetPitch(DefaultPitch * math.Pow(2, (float64(vm.v[x])-64)/48))
+
 	// Fx55 - LD [I], Vx
 	case 0x55:
 		for i := uint16(0); i <= uint16(x); i++ {
 			vm.memory[vm.i+i] = vm.v[i]
 		}
+		if vm.quirks.LoadStoreIncrementsI {
+			vm.i += uint16(x) + 1
+		}
 
 	// Fx65 - LD Vx, [I]
 	case 0x65:
 		for i := uint16(0); i <= uint16(x); i++ {
 			vm.v[i] = vm.memory[vm.i+i]
 		}
+		if vm.quirks.LoadStoreIncrementsI {
+			vm.i += uint16(x) + 1
+		}
+
+	// Fx75 - LD R, Vx (SUPER-CHIP)
+	case 0x75:
+		for i := uint16(0); i <= uint16(x); i++ {
+			vm.rpl[i] = vm.v[i]
+		}
+
+	// Fx85 - LD Vx, R (SUPER-CHIP)
+	case 0x85:
+		for i := uint16(0); i <= uint16(x); i++ {
+			vm.v[i] = vm.rpl[i]
+		}
+
+	// F000 NNNN - LD I, long addr (XO-CHIP)
+	case 0x00:
+		if x == 0 {
+			vm.i = vm.fetchOpcode()
+		}
 	}
 
 	return nil
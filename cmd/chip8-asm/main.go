@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kevhlee/chip8/chip8/asm"
+)
+
+func main() {
+	var (
+		disassemble = flag.Bool("d", false, "disassemble a ROM instead of assembling source")
+		output      = flag.String("o", "", "output file for assembly (defaults to <input>.ch8); -d always prints to stdout")
+	)
+	flag.Parse()
+
+	filename := flag.Arg(0)
+	if len(filename) == 0 {
+		exit("Usage: chip8-asm [-d] [-o output] <file>")
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		exit(err.Error())
+	}
+
+	if *disassemble {
+		for _, instr := range asm.Disassemble(data) {
+			line := fmt.Sprintf("0x%03X  %-6s %s", instr.Addr, instr.Mnemonic, instr.Operands)
+			if instr.Label != "" {
+				line = fmt.Sprintf("%s:\n%s", instr.Label, line)
+			}
+			fmt.Println(line)
+		}
+		return
+	}
+
+	program, err := asm.Assemble(string(data))
+	if err != nil {
+		exit(err.Error())
+	}
+
+	out := *output
+	if out == "" {
+		out = filename + ".ch8"
+	}
+	if err := os.WriteFile(out, program, 0644); err != nil {
+		exit(err.Error())
+	}
+}
+
+func exit(msg string) {
+	fmt.Fprintln(os.Stderr, msg)
+	os.Exit(1)
+}
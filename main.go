@@ -10,9 +10,13 @@ import (
 
 func main() {
 	var opts chip8.Options
+	var mode string
+	var quirks string
 
 	flag.IntVar(&opts.Scale, "scale", 8, "set the scaling factor of the screen")
 	flag.IntVar(&opts.TPS, "tps", 12, "set the number of CPU ticks per frame")
+	flag.StringVar(&mode, "mode", "chip8", "set the instruction set variant (chip8, schip, xochip)")
+	flag.StringVar(&quirks, "quirks", "auto", "set the quirks preset (auto, default, vip, schip, xochip)")
 	flag.Parse()
 
 	filename := flag.Arg(0)
@@ -20,6 +24,22 @@ func main() {
 		exit("Usage: chip8 <path to ROM>")
 	}
 
+	parsedMode, err := chip8.ParseMode(mode)
+	if err != nil {
+		exit(err.Error())
+	}
+	opts.Mode = parsedMode
+
+	if quirks == "auto" {
+		opts.AutoQuirks = true
+	} else {
+		parsedQuirks, err := chip8.ParseQuirksPreset(quirks)
+		if err != nil {
+			exit(err.Error())
+		}
+		opts.Quirks = parsedQuirks
+	}
+
 	if err := chip8.Start(filename, opts); err != nil {
 		exit(err.Error())
 	}